@@ -3,14 +3,14 @@ package setup
 import (
 	"context"
 	"fmt"
-	"log/slog"
 
 	"github.com/urfave/cli/v3"
 	cloudsql "ulist.app/ult/internal/cloud_sql"
+	"ulist.app/ult/internal/logging"
 )
 
 var (
-	logger = slog.Default().WithGroup("setup_command")
+	logger = logging.New("setup_command")
 )
 
 var Cmd = cli.Command{
@@ -1,15 +1,19 @@
 package commit_command
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"log/slog"
+	"io"
 	"os"
 
 	"github.com/urfave/cli/v3"
-	gitlab "gitlab.com/gitlab-org/api/client-go"
 	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/dryrun"
 	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/output"
 )
 
 const (
@@ -20,12 +24,24 @@ const (
 )
 
 var (
-	logger = slog.Default().WithGroup("commit_command")
+	logger = logging.New("commit_command")
 )
 
+// commitResult is the output schema of a committed file, WebURL is only set
+// when committing through the GitLab API.
+type commitResult struct {
+	File    string `json:"file" yaml:"file"`
+	Branch  string `json:"branch" yaml:"branch"`
+	Message string `json:"message" yaml:"message"`
+	Commit  string `json:"commit" yaml:"commit"`
+	WebURL  string `json:"web_url" yaml:"web_url"`
+	Skipped bool   `json:"skipped" yaml:"skipped"`
+	DryRun  bool   `json:"dry_run" yaml:"dry_run"`
+}
+
 var Cmd = cli.Command{
 	Name:   "commit",
-	Usage:  "commit a file change to the repository via the GitLab API",
+	Usage:  "commit a file change to the repository via the GitLab API (or the local clone with --backend git)",
 	Action: run,
 	Flags: []cli.Flag{
 		&cli.StringFlag{
@@ -42,11 +58,8 @@ var Cmd = cli.Command{
 }
 
 func run(ctx context.Context, cmd *cli.Command) error {
-	token, err := core.GetToken(cmd)
-	if err != nil {
-		return err
-	}
-	projectId, err := core.GetProjectID(cmd)
+	// the commit command always used the GitLab API before the backend setting existed
+	repo, err := core.NewRepository(cmd, core.RepositoryBackend(cmd, git.BackendGitlab))
 	if err != nil {
 		return err
 	}
@@ -54,6 +67,7 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	commitMessage := cmd.String(flagMessage)
 	branch := cmd.String(flagBranch)
 	if len(branch) == 0 {
+		// the local branch, the repository may be the GitLab project
 		currentBranch, err := git.GetCurrentBranch()
 		if err != nil {
 			return err
@@ -63,49 +77,41 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	}
 	logger.Info("updating file",
 		"file", filePath,
-		"project", projectId,
+		"backend", repo.Backend(),
 		"branch", branch,
 		"commit", commitMessage,
 	)
 
-	hasChanges, err := git.HasChanges(filePath)
+	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return err
 	}
-	if !hasChanges {
-		fmt.Println("\nThe given file has no changes. Skipping commit!")
-		return nil
+	committed, err := repo.ReadFile(branch, filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
 	}
 
-	appRepo, err := gitlab.NewClient(token)
-	if err != nil {
-		return err
+	result := commitResult{File: filePath, Branch: branch, Message: commitMessage, DryRun: dryrun.Enabled()}
+	if err == nil && bytes.Equal(content, committed) {
+		result.Skipped = true
+		return output.Print(result, func(w io.Writer) error {
+			_, err := fmt.Fprintln(w, "\nThe given file has no changes. Skipping commit!")
+			return err
+		})
 	}
 
-	content, err := os.ReadFile(filePath)
+	commit, err := repo.WriteFile(branch, filePath, content, commitMessage)
 	if err != nil {
 		return err
 	}
-	opt := gitlab.CreateCommitOptions{
-		AuthorName:    gitlab.Ptr(git.Name),
-		AuthorEmail:   gitlab.Ptr(git.Email),
-		CommitMessage: &commitMessage,
-		Branch:        &branch,
-		Actions: []*gitlab.CommitActionOptions{
-			{
-				Action:   gitlab.Ptr(gitlab.FileUpdate),
-				FilePath: &filePath,
-				Content:  gitlab.Ptr(string(content)),
-			},
-		},
-	}
-
-	commit, _, err := appRepo.Commits.CreateCommit(projectId, &opt)
-	if err != nil {
-		return fmt.Errorf("Error while trying to update %s file in repo: %w", filePath, err)
+	if dryrun.Enabled() {
+		return output.Print(result, func(w io.Writer) error { return nil })
 	}
+	result.Commit = commit.Hash
+	result.WebURL = commit.WebURL
 
-	fmt.Printf("Successfully commit updated file: %s\n", commit.String())
-
-	return nil
+	return output.Print(result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Successfully commit updated file: %s\n", commit.String())
+		return err
+	})
 }
@@ -0,0 +1,98 @@
+// Package config_command provides commands to inspect the layered ult
+// configuration (flags, environment variables, .ult.yaml and user config).
+package config_command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/output"
+)
+
+var showCmd = cli.Command{
+	Name:   "show",
+	Usage:  "print the effective configuration values and where each one came from",
+	Action: runShow,
+}
+
+var Cmd = cli.Command{
+	Name:   "config",
+	Usage:  "inspect the effective ult configuration",
+	Action: runShow,
+	Commands: []*cli.Command{
+		&showCmd,
+	},
+}
+
+// showResult is the output schema of config show.
+type showResult struct {
+	Files    []configFile   `json:"files" yaml:"files"`
+	Settings []shownSetting `json:"settings" yaml:"settings"`
+}
+
+// configFile is a configuration file that was loaded.
+type configFile struct {
+	Name string `json:"name" yaml:"name"`
+	Path string `json:"path" yaml:"path"`
+}
+
+// shownSetting is the effective value of a setting, Origin is the
+// environment variable or file it was read from.
+type shownSetting struct {
+	Name   string `json:"name" yaml:"name"`
+	Value  string `json:"value" yaml:"value"`
+	Source string `json:"source" yaml:"source"`
+	Origin string `json:"origin,omitempty" yaml:"origin,omitempty"`
+}
+
+func runShow(ctx context.Context, cmd *cli.Command) error {
+	result := showResult{Files: []configFile{}, Settings: []shownSetting{}}
+	for _, layer := range core.Config().Layers {
+		result.Files = append(result.Files, configFile{Name: layer.Name, Path: layer.Path})
+	}
+
+	for _, setting := range core.Settings {
+		resolved := core.Lookup(cmd, setting.Name)
+
+		value := resolved.Value
+		if setting.Secret && len(value) > 0 {
+			value = mask(value)
+		}
+		result.Settings = append(result.Settings, shownSetting{
+			Name:   setting.Name,
+			Value:  value,
+			Source: string(resolved.Source),
+			Origin: resolved.Origin,
+		})
+	}
+
+	return output.Print(result, func(w io.Writer) error {
+		for _, file := range result.Files {
+			fmt.Fprintf(w, "Using %s: %s\n", file.Name, file.Path)
+		}
+		fmt.Fprintln(w)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tVALUE\tSOURCE")
+		for _, setting := range result.Settings {
+			source := setting.Source
+			if len(setting.Origin) > 0 {
+				source = fmt.Sprintf("%s (%s)", setting.Source, setting.Origin)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", setting.Name, setting.Value, source)
+		}
+		return tw.Flush()
+	})
+}
+
+// mask hides all but the last four characters of a secret value.
+func mask(value string) string {
+	if len(value) <= 4 {
+		return "****"
+	}
+	return "****" + value[len(value)-4:]
+}
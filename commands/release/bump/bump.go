@@ -4,41 +4,81 @@ package bump
 
 import (
 	"context"
-	"errors"
+	"database/sql"
 	"fmt"
-	"log/slog"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v3"
-	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"ulist.app/ult/internal/autobump"
+	"ulist.app/ult/internal/bumpmarker"
 	cloudsql "ulist.app/ult/internal/cloud_sql"
 	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/output"
 	"ulist.app/ult/internal/playstore"
+	"ulist.app/ult/internal/pubspec"
 	"ulist.app/ult/internal/release"
+	"ulist.app/ult/internal/remote"
 	"ulist.app/ult/internal/version"
+	"ulist.app/ult/internal/versionsync"
 )
 
 // Command flag constants
 const (
 	flagFetch           = "fetch"
 	flagFetchForRelease = "play-store"
-	flagCredentialsPath = "credentials"
+	flagCredentialsPath = core.CredentialsFlag
+	flagPlayPackage     = core.PlayPackageFlag
 	flagOnce            = "once"
 	flagTarget          = "target"
 	flagSource          = "source"
+	flagPreRelease      = "pre-release"
+	flagReserve         = "reserve"
+	flagReserveTTL      = "reserve-ttl"
+	flagPipeline        = core.PipelineFlag
+	flagCommitSHA       = core.CommitSHAFlag
+	flagBranch          = core.BranchFlag
+	flagSince           = "since"
+	flagMarker          = "marker"
+	flagMarkerRegex     = "marker-regex"
+	flagMarkerTrailer   = "marker-trailer"
 )
 
+// bumpAuto is the bump type argument that infers the bump type from the
+// configured bump rules.
+const bumpAuto = "auto"
+
 var (
-	logger = slog.Default().WithGroup("bump_command")
+	logger = logging.New("bump_command")
 )
 
+// bumpResult is the output schema of a version bump.
+type bumpResult struct {
+	File            string   `json:"file" yaml:"file"`
+	BumpType        string   `json:"bump_type" yaml:"bump_type"`
+	PreviousVersion string   `json:"previous_version" yaml:"previous_version"`
+	Version         string   `json:"version" yaml:"version"`
+	ReservedUntil   string   `json:"reserved_until,omitempty" yaml:"reserved_until,omitempty"`
+	Commit          string   `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Tag             string   `json:"tag,omitempty" yaml:"tag,omitempty"`
+	TagMoved        bool     `json:"tag_moved" yaml:"tag_moved"`
+	Pushed          bool     `json:"pushed" yaml:"pushed"`
+	Reasons         []string `json:"reasons,omitempty" yaml:"reasons,omitempty"`
+	Skipped         bool     `json:"skipped" yaml:"skipped"`
+	DryRun          bool     `json:"dry_run" yaml:"dry_run"`
+}
+
 // Cmd defines the version command for CLI
 var Cmd = cli.Command{
 	Name:   "bump",
-	Usage:  "increment the version in pubspec.yaml (build, patch, minor, or major)",
+	Usage:  "increment the version in pubspec.yaml (year, milestone, major, minor, patch, build, prerelease or promote, as supported by the version scheme, or auto to infer it from the bump rules)",
 	Action: run,
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.BoolFlag{
 			Name:  flagFetch,
 			Usage: "fetch the latest build number from an external server before incrementing (defaults to local pubspec.yaml value)",
@@ -51,9 +91,26 @@ var Cmd = cli.Command{
 			Name:  flagCredentialsPath,
 			Usage: "path to the Google Play Store service account credentials JSON file",
 		},
+		&cli.StringFlag{
+			Name:  flagPlayPackage,
+			Usage: "package name of the app on the Google Play Store (used by --play-store)",
+			Value: playstore.DefaultPackageName,
+		},
 		&cli.BoolFlag{
 			Name:  flagOnce,
-			Usage: "skip bump if a previous bump commit already exists between --target and --source",
+			Usage: "skip bump if a previous bump commit already exists between --target and --source (uses the GitLab API unless --backend git)",
+		},
+		&cli.StringFlag{
+			Name:  flagMarker,
+			Usage: "text in the subject of bump commits (defaults to '" + bumpmarker.DefaultText + "', or the bump-marker of .ult.yaml)",
+		},
+		&cli.StringFlag{
+			Name:  flagMarkerRegex,
+			Usage: "regex matching the message of bump commits",
+		},
+		&cli.StringFlag{
+			Name:  flagMarkerTrailer,
+			Usage: "trailer of bump commits, e.g. 'Ult-Bump' or 'Ult-Bump: build'",
 		},
 		&cli.StringFlag{
 			Name:  flagSource,
@@ -63,78 +120,125 @@ var Cmd = cli.Command{
 			Name:  flagTarget,
 			Usage: "target branch name or commit SHA (the older end of the range; required with --once)",
 		},
-	},
+		&cli.StringFlag{
+			Name:  flagPreRelease,
+			Usage: "make the bumped version a pre-release of this channel, e.g. rc, beta or qa (2025.200.02-rc.1+01)",
+		},
+		&cli.BoolFlag{
+			Name:  flagReserve,
+			Usage: "atomically reserve the bumped build number in Cloud SQL, moving past builds already released or reserved by other pipelines",
+		},
+		&cli.DurationFlag{
+			Name:  flagReserveTTL,
+			Usage: "how long an unused reservation holds its build number (used with --reserve)",
+			Value: release.DefaultReservationTTL,
+		},
+		&cli.StringFlag{
+			Name:  flagPipeline,
+			Usage: "pipeline recorded with the reservation (defaults to CI_PIPELINE_URL)",
+		},
+		&cli.StringFlag{
+			Name:  flagCommitSHA,
+			Usage: "commit recorded with the reservation (defaults to CI_COMMIT_SHA)",
+		},
+		&cli.StringFlag{
+			Name:  flagBranch,
+			Usage: "branch matched against the branch rules of 'auto' and pushed to by --push and --api (defaults to CI_MERGE_REQUEST_SOURCE_BRANCH_NAME, CI_COMMIT_BRANCH or the current branch)",
+		},
+		&cli.StringFlag{
+			Name:  flagSince,
+			Usage: "commits after this ref are matched against the commit rules of 'auto' (defaults to the latest tag)",
+		},
+	}, publishFlags...),
 }
 
 // run is the main entry point for the version command that reads the pubspec.yaml file,
 // updates the version according to the specified bump type, and writes the changes back.
 // It handles fetching the latest build number from the Play Store if requested.
 func run(ctx context.Context, cmd *cli.Command) error {
-	const pubspecPath = "pubspec.yaml"
+	const pubspecPath = pubspec.DefaultPath
 
 	logger.Debug("Starting version command",
 		"fetch for qa", cmd.Bool(flagFetchForRelease))
 
-	bumpType, err := version.ParseBumpType(cmd.Args().First())
-	if err != nil {
-		logger.Error("Failed to parse bump type", "error", err)
-		return fmt.Errorf("parsing bump type: %w", err)
-	}
+	auto := strings.EqualFold(cmd.Args().First(), bumpAuto)
 
-	if cmd.Bool(flagOnce) {
-		target := cmd.String(flagTarget)
-		if len(target) == 0 {
-			return errors.New("when using '--once' flag a target branch name or commit sha must be provided '--target=target-name-in-remote'")
+	var bumpType version.BumpType
+	if !auto {
+		var err error
+		bumpType, err = version.ParseBumpType(cmd.Args().First())
+		if err != nil {
+			logger.Error("Failed to parse bump type", "error", err)
+			return fmt.Errorf("parsing bump type: %w", err)
 		}
+	}
 
-		source := cmd.String(flagSource)
-		if len(source) == 0 {
-			return errors.New("when using '--once' flag a source branch name or commit sha must be provided '--source=source-name-in-remote'")
-		}
+	marker, err := loadMarker(cmd)
+	if err != nil {
+		return err
+	}
 
-		token, err := core.GetToken(cmd)
-		if err != nil {
-			return errors.New("when using '--once' flag a token must be provided '--token=my-token'")
-		}
-		projectId, err := core.GetProjectID(cmd)
-		if err != nil {
-			return errors.New("when using '--once' flag a projectId must be provided '--project-id=000000000'")
-		}
+	publishOpts, err := newPublishOptions(cmd, marker)
+	if err != nil {
+		return err
+	}
+
+	result := bumpResult{File: pubspecPath, BumpType: bumpAuto, DryRun: dryrun.Enabled()}
+	if !auto {
+		result.BumpType = bumpType.String()
+	}
 
-		alreadyBumped, err := didAlreadyBumpGitlabAPI(target, source, projectId, token)
+	if cmd.Bool(flagOnce) {
+		alreadyBumped, err := didAlreadyBump(cmd, marker)
 		if err != nil {
 			return err
 		}
 
 		if alreadyBumped {
-			fmt.Println("Previous bump was found. Skipping a new one...")
-			return nil
+			result.Skipped = true
+			return output.Print(result, func(w io.Writer) error {
+				_, err := fmt.Fprintln(w, "Previous bump was found. Skipping a new one...")
+				return err
+			})
 		}
 
-		fmt.Println("No previous bump was found. Creating a new one...")
+		output.Println("No previous bump was found. Creating a new one...")
 	}
 
-	contents, err := os.ReadFile(pubspecPath)
+	if auto && publishOpts.remote {
+		return fmt.Errorf("'%s' reads the local commits and can not be used with '--%s'", bumpAuto, flagRemote)
+	}
+
+	var repo *git.Gitlab
+	var file *pubspec.File
+	if publishOpts.remote {
+		if repo, err = newRemoteRepository(cmd); err != nil {
+			return err
+		}
+		file, err = remote.ReadPubspec(repo, pubspecPath)
+	} else {
+		file, err = pubspec.Read(pubspecPath)
+	}
 	if err != nil {
 		logger.Error("Failed to read pubspec.yaml", "error", err)
 		return fmt.Errorf("reading pubspec.yaml: %w", err)
 	}
 
-	lines := strings.Split(string(contents), "\n")
-	logger.Debug("Read pubspec.yaml", "lineCount", len(lines))
-
-	version, idx, err := version.FetchFromLines(lines)
+	version, err := file.Version()
 	if err != nil {
 		logger.Error("Failed to parse version", "error", err)
 		return fmt.Errorf("parsing version: %w", err)
 	}
 
-	logger.Info("Found version in pubspec", "version", version, "at line idx", idx)
+	logger.Info("Found version in pubspec", "version", version, "line", file.Line())
 
 	if cmd.Bool(flagFetch) {
 		if cmd.Bool(flagFetchForRelease) {
-			secretsPath := cmd.String(flagCredentialsPath)
-			latest, err := fetchLatestReleaseBuild(secretsPath)
+			secretsPath, err := core.GetRequiredString(cmd, flagCredentialsPath)
+			if err != nil {
+				return err
+			}
+			latest, err := fetchLatestReleaseBuild(secretsPath, core.GetString(cmd, flagPlayPackage))
 			if err != nil {
 				return err
 			}
@@ -147,55 +251,111 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		}
 	}
 
-	version.Bump(bumpType)
-
-	// Update version in pubspec lines
-	lines[idx] = fmt.Sprintf("version: %s", version)
+	if auto {
+		decision, err := inferBumpType(cmd, *version)
+		if err != nil {
+			return err
+		}
+		bumpType = decision.BumpType
+		result.BumpType = bumpType.String()
+		result.Reasons = decision.Reasons
+		for _, reason := range decision.Reasons {
+			output.Println("auto:", reason)
+		}
+		output.Println("auto: bumping", bumpType)
+	}
 
-	// Write back to file with original permissions
-	if err := writeFile(pubspecPath, lines); err != nil {
-		return err
+	result.PreviousVersion = version.String()
+	if err := version.BumpInChannel(bumpType, cmd.String(flagPreRelease)); err != nil {
+		return fmt.Errorf("bumping version: %w", err)
 	}
 
-	logger.Info("Updated pubspec.yaml with new version", "newVersion", version)
-	return nil
-}
+	if cmd.Bool(flagReserve) {
+		reservation, err := reserveBuild(cmd, *version)
+		if err != nil {
+			return err
+		}
+		if reservation.Version.Build != version.Build {
+			logger.Warn("build already taken by another release or pipeline, using the next free build",
+				"build", version.Build, "reserved", reservation.Version.Build)
+		}
+		version = &reservation.Version
+		result.ReservedUntil = reservation.ExpiresAt.Format(time.RFC3339)
+	}
+	result.Version = version.String()
 
-// writeFile writes the provided lines back to the specified file path,
-// preserving the original file permissions. It returns an error if
-// getting file info or writing to the file fails.
-func writeFile(path string, lines []string) error {
-	logger.Debug("Writing in pubspec file", "path", path, "lineCount", len(lines))
+	if err := checkTag(cmd, publishOpts, *version); err != nil {
+		return err
+	}
 
-	fileInfo, err := os.Stat(path)
+	targets, err := versionsync.LoadTargets(core.Config())
 	if err != nil {
-		logger.Error("Failed to get pubspec file info", "path", path, "error", err)
-		return fmt.Errorf("getting file info: %w", err)
+		return fmt.Errorf("loading version sync targets: %w", err)
+	}
+	if repo != nil {
+		if err := publishRemote(cmd, publishOpts, repo, file, targets, *version, &result); err != nil {
+			return err
+		}
+		return printResult(result)
 	}
 
-	content := strings.Join(lines, "\n")
-	err = os.WriteFile(path, []byte(content), fileInfo.Mode())
+	paths := []string{pubspecPath}
+	for _, target := range targets {
+		paths = append(paths, target.Path)
+	}
+	original, err := takeSnapshot(paths)
 	if err != nil {
-		logger.Error("Failed to write pubspec file", "path", path, "error", err)
-		return fmt.Errorf("writing file: %w", err)
+		return err
 	}
 
-	logger.Debug("Pubspec file written successfully", "path", path, "bytes", len(content))
-	return nil
+	if err := versionsync.Update(file, targets, *version); err != nil {
+		return err
+	}
+	logger.Info("Updated pubspec.yaml with new version", "newVersion", version)
+
+	if publishOpts.commit {
+		if err := publish(cmd, publishOpts, paths, *version, &result); err != nil {
+			original.restore()
+			return err
+		}
+	}
+
+	return printResult(result)
+}
+
+func printResult(result bumpResult) error {
+	return output.Print(result, func(w io.Writer) error {
+		if result.DryRun {
+			return nil
+		}
+		fmt.Fprintf(w, "updated %s with version: %s\n", result.File, result.Version)
+		if len(result.Commit) > 0 {
+			fmt.Fprintf(w, "committed %s\n", result.Commit)
+		}
+		if result.TagMoved {
+			fmt.Fprintf(w, "moved tag %s\n", result.Tag)
+		} else if len(result.Tag) > 0 {
+			fmt.Fprintf(w, "tagged %s\n", result.Tag)
+		}
+		if result.Pushed {
+			fmt.Fprintln(w, "pushed the version commit")
+		}
+		return nil
+	})
 }
 
 // fetchLatestReleaseBuild runs a fastlane command to retrieve the
 // latest build number from the Google Play Store for the specified app.
 // It parses the output and returns the build number as an integer.
 // Returns an error if the command fails or the output cannot be parsed.
-func fetchLatestReleaseBuild(path string) (int64, error) {
+func fetchLatestReleaseBuild(path, packageName string) (int64, error) {
 	logger.Info("Fetching latest build from Play Store")
 
 	contents, err := os.ReadFile(path)
 	if err != nil {
 		return 0, err
 	}
-	latest, err := playstore.GetVersionFromLatestRelease(contents, "app.ulist")
+	latest, err := playstore.GetVersionFromLatestRelease(contents, packageName)
 	if err != nil {
 		return 0, err
 	}
@@ -203,45 +363,84 @@ func fetchLatestReleaseBuild(path string) (int64, error) {
 	return latest, nil
 }
 
-func fetchLatestDevelopmentVersion() (*version.Version, error) {
-	db, err := cloudsql.ConnectWithConnector()
-	if err != nil {
-		return nil, err
+// inferBumpType applies the bump rules to the branch and the commits since
+// the latest tag of the local repository.
+func inferBumpType(cmd *cli.Command, v version.Version) (autobump.Decision, error) {
+	scheme := v.Scheme
+	if scheme == nil {
+		scheme = version.Default()
 	}
-
-	release, err := release.FetchLatestRelease(db)
+	rules, err := autobump.LoadRules(core.Config(), scheme)
 	if err != nil {
-		return nil, err
+		return autobump.Decision{}, fmt.Errorf("loading bump rules: %w", err)
 	}
 
-	return &release.Version, nil
-}
+	branch := core.GetString(cmd, flagBranch)
+	if len(branch) == 0 {
+		branch, err = git.GetCurrentBranch()
+		if err != nil {
+			return autobump.Decision{}, err
+		}
+	}
 
-// Will search for bump commit in all commits there are on `source` but not on `target` (same as git log --oneline source --not target).
-func didAlreadyBumpGitlabAPI(target, source, projectId, token string) (bool, error) {
-	appRepo, err := gitlab.NewClient(token)
+	since := cmd.String(flagSince)
+	if len(since) == 0 {
+		since, err = git.GetLatestTag("HEAD", "")
+		if err != nil {
+			return autobump.Decision{}, err
+		}
+	}
+	commits, err := git.GetCommitMessages(since, "HEAD")
 	if err != nil {
-		return false, fmt.Errorf("Failed to create client: %v", err)
+		return autobump.Decision{}, err
 	}
+	logger.Debug("Inferring bump type", "branch", branch, "since", since, "commits", len(commits))
+
+	return autobump.Infer(rules, autobump.Input{
+		Version: v,
+		Branch:  branch,
+		Commits: commits,
+		Now:     time.Now(),
+	})
+}
 
-	opt := &gitlab.CompareOptions{
-		From: gitlab.Ptr(target),
-		To:   gitlab.Ptr(source),
+// reserveBuild claims the build number of the version in Cloud SQL so
+// parallel pipelines never produce the same build.
+func reserveBuild(cmd *cli.Command, v version.Version) (*release.Reservation, error) {
+	// a dry-run only prints the reservation, so no database connection is needed
+	var db *sql.DB
+	if !dryrun.Enabled() {
+		var err error
+		db, err = cloudsql.ConnectWithConnector()
+		if err != nil {
+			return nil, fmt.Errorf("not able to connect with database to reserve build: %w", err)
+		}
+		defer db.Close()
 	}
-	compare, _, err := appRepo.Repositories.Compare(projectId, opt)
+
+	reservation, err := release.ReserveBuild(db, v,
+		core.GetString(cmd, flagPipeline),
+		core.GetString(cmd, flagCommitSHA),
+		cmd.Duration(flagReserveTTL),
+	)
 	if err != nil {
-		return false, fmt.Errorf("Error fetching commit diff from gitlab branches: %v", err)
+		return nil, fmt.Errorf("reserving build: %w", err)
 	}
 
-	for _, commit := range compare.Commits {
-		println(commit.Title)
+	logger.Info("Reserved build", "reservation", reservation)
+	return reservation, nil
+}
+
+func fetchLatestDevelopmentVersion() (*version.Version, error) {
+	db, err := cloudsql.ConnectWithConnector()
+	if err != nil {
+		return nil, err
 	}
 
-	for _, commit := range compare.Commits {
-		if strings.Contains(commit.Title, "bump version [skip ci]") {
-			return true, nil
-		}
+	release, err := release.FetchLatestRelease(db)
+	if err != nil {
+		return nil, err
 	}
 
-	return false, nil
+	return &release.Version, nil
 }
@@ -0,0 +1,61 @@
+package bump
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+	"ulist.app/ult/internal/bumpmarker"
+	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/git"
+)
+
+// loadMarker returns the marker given by the flags, or the one configured in
+// .ult.yaml when no marker flag is set.
+func loadMarker(cmd *cli.Command) (bumpmarker.Marker, error) {
+	if cmd.IsSet(flagMarker) || cmd.IsSet(flagMarkerRegex) || cmd.IsSet(flagMarkerTrailer) {
+		return bumpmarker.New(cmd.String(flagMarker), cmd.String(flagMarkerRegex), cmd.String(flagMarkerTrailer))
+	}
+
+	marker, err := bumpmarker.Load(core.Config())
+	if err != nil {
+		return bumpmarker.Marker{}, fmt.Errorf("loading bump marker: %w", err)
+	}
+	return marker, nil
+}
+
+// didAlreadyBump reports whether a bump commit exists on --source but not on
+// --target, looked up with the backend selected by --backend.
+func didAlreadyBump(cmd *cli.Command, marker bumpmarker.Marker) (bool, error) {
+	target := cmd.String(flagTarget)
+	if len(target) == 0 {
+		return false, errors.New("when using '--once' flag a target branch name or commit sha must be provided '--target=target-name-in-remote'")
+	}
+
+	source := cmd.String(flagSource)
+	if len(source) == 0 {
+		return false, errors.New("when using '--once' flag a source branch name or commit sha must be provided '--source=source-name-in-remote'")
+	}
+
+	// --once always used the GitLab API before the backend setting existed
+	repo, err := core.NewRepository(cmd, core.RepositoryBackend(cmd, git.BackendGitlab))
+	if err != nil {
+		return false, fmt.Errorf("when using '--once' flag: %w", err)
+	}
+
+	commits, err := repo.CompareRange(target, source)
+	if err != nil {
+		return false, err
+	}
+	messages := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		messages = append(messages, commit.Message)
+	}
+
+	logger.Debug("Searching bump commit", "target", target, "source", source, "commits", len(messages), "marker", marker)
+	message, found := marker.FindIn(messages)
+	if found {
+		logger.Info("Found bump commit", "message", message)
+	}
+	return found, nil
+}
@@ -0,0 +1,390 @@
+package bump
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"ulist.app/ult/internal/bumpmarker"
+	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/pubspec"
+	"ulist.app/ult/internal/remote"
+	"ulist.app/ult/internal/tagmessage"
+	"ulist.app/ult/internal/version"
+	"ulist.app/ult/internal/versionsync"
+)
+
+const (
+	flagCommit        = "commit"
+	flagTag           = "tag"
+	flagPush          = "push"
+	flagApi           = "api"
+	flagCommitMessage = "commit-message"
+	flagTagName       = "tag-name"
+	flagTagMessage    = "tag-message"
+	flagMove          = "move"
+	flagRemote        = "remote"
+	flagRef           = "ref"
+)
+
+const (
+	// defaultCommitMessage is matched by the default bump marker
+	defaultCommitMessage = bumpmarker.DefaultText
+	defaultTagName       = version.PlaceholderVersion
+	gitRemote            = "origin"
+)
+
+var publishFlags = []cli.Flag{
+	&cli.BoolFlag{
+		Name:  flagCommit,
+		Usage: "commit the version change (pubspec.yaml and version sync targets)",
+	},
+	&cli.BoolFlag{
+		Name:  flagTag,
+		Usage: "tag the version commit (requires --commit)",
+	},
+	&cli.BoolFlag{
+		Name:  flagPush,
+		Usage: "push the version commit and tag to origin (requires --commit)",
+	},
+	&cli.BoolFlag{
+		Name:  flagApi,
+		Usage: "commit and tag through the GitLab API instead of local git (requires --token and --project-id; implies --push)",
+	},
+	&cli.BoolFlag{
+		Name:  flagRemote,
+		Usage: "read pubspec.yaml and the version sync targets from --ref through the GitLab API and commit the change back, no checkout needed (requires --token and --project-id; implies --commit and --push)",
+	},
+	&cli.StringFlag{
+		Name:  flagRef,
+		Usage: "branch read and committed to by --remote (defaults to --branch)",
+	},
+	&cli.StringFlag{
+		Name:  flagCommitMessage,
+		Usage: "message of the version commit, supports the {version} placeholders (defaults to '" + defaultCommitMessage + "', or a message matched by the bump marker)",
+	},
+	&cli.StringFlag{
+		Name:  flagTagName,
+		Usage: "name of the version tag, supports the {version} placeholders (defaults to '" + defaultTagName + "')",
+	},
+	&cli.BoolFlag{
+		Name:  flagMove,
+		Usage: "move the version tag when it already exists, the old and new commit are logged for auditing (requires --tag)",
+	},
+	&cli.StringFlag{
+		Name:  flagTagMessage,
+		Usage: "create an annotated version tag with this message, supports {tag}, {changelog} (commits since the previous tag, local git only), {pipeline_url} and the {version} placeholders (signed tags default to '" + tagmessage.DefaultTemplate + "')",
+	},
+}
+
+// publishOptions selects what is done with the version change after it is
+// written to the working tree.
+type publishOptions struct {
+	commit  bool
+	tag     bool
+	push    bool
+	api     bool
+	remote  bool
+	message string
+	tagName string
+	// tagMessage is the template of the tag message, empty for lightweight tags
+	tagMessage string
+	move       bool
+}
+
+func newPublishOptions(cmd *cli.Command, marker bumpmarker.Marker) (publishOptions, error) {
+	opts := publishOptions{
+		commit:     cmd.Bool(flagCommit),
+		tag:        cmd.Bool(flagTag),
+		push:       cmd.Bool(flagPush),
+		api:        cmd.Bool(flagApi),
+		remote:     cmd.Bool(flagRemote),
+		message:    core.GetString(cmd, flagCommitMessage),
+		tagName:    core.GetString(cmd, flagTagName),
+		tagMessage: core.GetString(cmd, flagTagMessage),
+		move:       cmd.Bool(flagMove),
+	}
+
+	if opts.remote {
+		opts.commit, opts.push = true, true
+	}
+	if opts.remote && opts.api {
+		return opts, fmt.Errorf("'--%s' and '--%s' can not be used together", flagRemote, flagApi)
+	}
+	if (opts.tag || opts.push || opts.api) && !opts.commit {
+		return opts, fmt.Errorf("'--%s', '--%s' and '--%s' require '--%s'", flagTag, flagPush, flagApi, flagCommit)
+	}
+	if opts.move && !opts.tag {
+		return opts, fmt.Errorf("'--%s' requires '--%s'", flagMove, flagTag)
+	}
+	if opts.tag && (opts.api || opts.remote) && git.SigningEnabled() {
+		return opts, fmt.Errorf("the GitLab API can not sign tags, '--%s' and '--%s' can not be used with signing", flagApi, flagRemote)
+	}
+	if len(opts.message) == 0 && opts.commit {
+		var err error
+		if opts.message, err = defaultMessage(marker); err != nil {
+			return opts, err
+		}
+	}
+	if len(opts.tagName) == 0 {
+		opts.tagName = defaultTagName
+	}
+	return opts, nil
+}
+
+// defaultMessage returns the default commit message with the marker, so
+// --once finds the commits made by a previous bump. A regex can't be turned
+// into a message, the message must then be given with --commit-message.
+func defaultMessage(marker bumpmarker.Marker) (string, error) {
+	message := defaultCommitMessage
+	if len(marker.Text) > 0 && !strings.Contains(message, marker.Text) {
+		message = marker.Text + " " + message
+	}
+	if len(marker.Trailer) > 0 {
+		message += "\n\n" + marker.Trailer
+		if !strings.Contains(marker.Trailer, ":") {
+			message += ": true"
+		}
+	}
+
+	if !marker.Match(message) {
+		return "", fmt.Errorf("the default commit message %q is not matched by the bump marker (%s), provide one with '--%s'", message, marker, flagCommitMessage)
+	}
+	return message, nil
+}
+
+// snapshot holds the content of files before they were changed, nil for
+// files that did not exist, so the change can be rolled back.
+type snapshot map[string][]byte
+
+func takeSnapshot(paths []string) (snapshot, error) {
+	s := snapshot{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		s[path] = content
+	}
+	return s, nil
+}
+
+func (s snapshot) restore() {
+	if dryrun.Enabled() {
+		return
+	}
+
+	for path, content := range s {
+		var err error
+		if content == nil {
+			err = os.Remove(path)
+		} else {
+			// an existing file keeps its mode
+			err = os.WriteFile(path, content, 0o644)
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Error("Failed to restore file", "path", path, "error", err)
+		}
+	}
+}
+
+// checkTag fails before anything is committed when the version tag already
+// exists and may not be moved, the new version commit would move it.
+func checkTag(cmd *cli.Command, opts publishOptions, v version.Version) error {
+	if !opts.tag || opts.move {
+		return nil
+	}
+
+	backend := git.BackendGit
+	if opts.api || opts.remote {
+		backend = git.BackendGitlab
+	}
+	repo, err := core.NewRepository(cmd, backend)
+	if err != nil {
+		return err
+	}
+
+	tagName := v.Expand(opts.tagName)
+	commit, err := repo.TagTarget(tagName)
+	if err != nil {
+		return err
+	}
+	if len(commit) > 0 {
+		return fmt.Errorf("tag %s already points to %s: %w, pass '--%s' to move it", tagName, commit, git.ErrTagExists, flagMove)
+	}
+	return nil
+}
+
+// publish commits, tags and pushes the version change. On failure the steps
+// already done are undone, restoring the files is left to the caller.
+func publish(cmd *cli.Command, opts publishOptions, paths []string, v version.Version, result *bumpResult) error {
+	message, tagName := v.Expand(opts.message), v.Expand(opts.tagName)
+	tagData := tagmessage.Data{
+		Tag:         tagName,
+		Version:     &v,
+		PipelineURL: core.GetString(cmd, core.PipelineFlag),
+	}
+
+	branch := core.GetString(cmd, flagBranch)
+	if len(branch) == 0 && (opts.push || opts.api) {
+		var err error
+		branch, err = git.GetCurrentBranch()
+		if err != nil {
+			return err
+		}
+		if len(branch) == 0 {
+			return fmt.Errorf("not on a branch, the branch to push to must be provided '--%s=main'", flagBranch)
+		}
+	}
+
+	if opts.api {
+		tagMessage := tagmessage.Build(opts.tagMessage, tagData, "", false)
+		return publishGitlabAPI(cmd, opts, paths, branch, message, tagName, tagMessage, result)
+	}
+	return publishLocal(opts, paths, branch, message, tagData, result)
+}
+
+func publishLocal(opts publishOptions, paths []string, branch, message string, tagData tagmessage.Data, result *bumpResult) error {
+	head, err := git.GetHeadCommit()
+	if err != nil {
+		return err
+	}
+
+	rollback := func(cause error, tag *git.TagChange) error {
+		logger.Warn("Rolling back version commit", "head", head, "error", cause)
+		if tag != nil {
+			if err := git.RestoreTag(tag); err != nil {
+				logger.Error("Failed to restore tag", "tag", tag.Tag, "error", err)
+			}
+		}
+		if err := git.ResetTo(head); err != nil {
+			logger.Error("Failed to reset branch", "head", head, "error", err)
+		}
+		return fmt.Errorf("%w (the version change was rolled back)", cause)
+	}
+
+	if err := git.CommitFiles(message, paths...); err != nil {
+		return rollback(err, nil)
+	}
+	if !dryrun.Enabled() {
+		if result.Commit, err = git.GetHeadCommit(); err != nil {
+			return rollback(err, nil)
+		}
+	}
+
+	// only the version tag is pushed, a moved tag is force pushed
+	refspecs := []string{"HEAD:refs/heads/" + branch}
+	var tag *git.TagChange
+	if opts.tag {
+		// the changelog of the tag message ends with the version commit
+		tagMessage := tagmessage.Build(opts.tagMessage, tagData, "HEAD", true)
+		if tag, err = git.CreateTag(tagData.Tag, "HEAD", tagMessage, opts.move); err != nil {
+			return rollback(err, nil)
+		}
+		result.Tag, result.TagMoved = tag.Tag, tag.Moved()
+
+		refspec := "refs/tags/" + tag.Tag
+		if tag.Moved() {
+			refspec = "+" + refspec
+		}
+		refspecs = append(refspecs, refspec)
+	}
+
+	if opts.push {
+		if err := git.PushRefs(gitRemote, refspecs...); err != nil {
+			return rollback(err, tag)
+		}
+		result.Pushed = true
+	}
+
+	return nil
+}
+
+func publishGitlabAPI(cmd *cli.Command, opts publishOptions, paths []string, branch, message, tagName, tagMessage string, result *bumpResult) error {
+	appRepo, err := core.NewGitlabClient(cmd)
+	if err != nil {
+		return err
+	}
+	projectId, err := core.GetProjectID(cmd)
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		files[path] = content
+	}
+
+	repo := git.NewGitlab(appRepo, projectId, branch)
+	commit, err := repo.WriteFiles(branch, message, files)
+	if err != nil {
+		return fmt.Errorf("committing version change to %s: %w", branch, err)
+	}
+	if !dryrun.Enabled() {
+		result.Commit = commit.Hash
+		result.Pushed = true
+	}
+
+	if opts.tag {
+		tag, err := remote.TagCommit(repo, tagName, commit.Hash, tagMessage, opts.move)
+		if err != nil {
+			return err
+		}
+		result.TagMoved = tag.Moved()
+		result.Tag = tagName
+	}
+
+	return nil
+}
+
+// newRemoteRepository returns the branch of the GitLab project used by --remote.
+func newRemoteRepository(cmd *cli.Command) (*git.Gitlab, error) {
+	ref := cmd.String(flagRef)
+	if len(ref) == 0 {
+		ref = core.GetString(cmd, flagBranch)
+	}
+	if len(ref) == 0 {
+		return nil, fmt.Errorf("when using '--%s' flag the branch must be provided '--%s=main'", flagRemote, flagRef)
+	}
+	return remote.Open(cmd, ref)
+}
+
+// publishRemote commits the version change of the pubspec read from the
+// remote branch and tags the commit.
+func publishRemote(cmd *cli.Command, opts publishOptions, repo *git.Gitlab, file *pubspec.File, targets []versionsync.Target, v version.Version, result *bumpResult) error {
+	commitID, err := remote.UpdateVersion(repo, file, targets, v, v.Expand(opts.message))
+	if err != nil {
+		return err
+	}
+	result.Commit = commitID
+	result.Pushed = len(commitID) > 0
+
+	if opts.tag {
+		if len(commitID) == 0 && !dryrun.Enabled() {
+			branch, _ := repo.CurrentBranch()
+			logger.Warn("Nothing was committed, skipping tag", "ref", branch)
+			return nil
+		}
+		tagName := v.Expand(opts.tagName)
+		tagMessage := tagmessage.Build(opts.tagMessage, tagmessage.Data{
+			Tag:         tagName,
+			Version:     &v,
+			PipelineURL: core.GetString(cmd, core.PipelineFlag),
+		}, commitID, false)
+		tag, err := remote.TagCommit(repo, tagName, commitID, tagMessage, opts.move)
+		if err != nil {
+			return err
+		}
+		result.TagMoved = tag.Moved()
+		result.Tag = tagName
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+// Package check provides the pre-flight validation that the pubspec.yaml
+// version is newer than every version already released.
+package check
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+	cloudsql "ulist.app/ult/internal/cloud_sql"
+	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/output"
+	"ulist.app/ult/internal/playstore"
+	"ulist.app/ult/internal/pubspec"
+	"ulist.app/ult/internal/release"
+	"ulist.app/ult/internal/tagchannel"
+	"ulist.app/ult/internal/version"
+)
+
+const (
+	flagPath            = "path"
+	flagSources         = "sources"
+	flagApi             = "api"
+	flagCredentialsPath = core.CredentialsFlag
+	flagPlayPackage     = core.PlayPackageFlag
+)
+
+// Sources the pubspec version is checked against.
+const (
+	sourceCloudSQL  = "cloud-sql"
+	sourcePlayStore = "play-store"
+	sourceQATag     = "qa-tag"
+)
+
+// Status of a single source check.
+const (
+	statusOK       = "ok"
+	statusConflict = "conflict"
+	statusError    = "error"
+)
+
+var (
+	logger = logging.New("check_command")
+
+	allSources = []string{sourceCloudSQL, sourcePlayStore, sourceQATag}
+)
+
+// checkResult is the output schema of the release check.
+type checkResult struct {
+	File    string         `json:"file" yaml:"file"`
+	Version string         `json:"version" yaml:"version"`
+	OK      bool           `json:"ok" yaml:"ok"`
+	Sources []sourceResult `json:"sources" yaml:"sources"`
+}
+
+// sourceResult is the outcome of comparing pubspec against one source.
+// Latest is empty when the source has no released version yet.
+type sourceResult struct {
+	Source  string `json:"source" yaml:"source"`
+	Latest  string `json:"latest" yaml:"latest"`
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+var Cmd = cli.Command{
+	Name: "check",
+	Usage: "verify the pubspec.yaml version is strictly greater than the latest Cloud SQL release, " +
+		"the highest Play Store version code and the newest QA tag",
+	Action: run,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  flagPath,
+			Usage: "path to the pubspec.yaml file",
+			Value: pubspec.DefaultPath,
+		},
+		&cli.StringSliceFlag{
+			Name:  flagSources,
+			Usage: "sources to check against: cloud-sql, play-store and qa-tag",
+			Value: allSources,
+		},
+		&cli.BoolFlag{
+			Name:  flagApi,
+			Usage: "fetch the QA tags via the GitLab API (requires --token and --project-id; same as --backend gitlab)",
+		},
+		&cli.StringFlag{
+			Name:  flagCredentialsPath,
+			Usage: "path to the Google Play Store service account credentials JSON file (required by the play-store source)",
+		},
+		&cli.StringFlag{
+			Name:  flagPlayPackage,
+			Usage: "package name of the app on the Google Play Store (used by the play-store source)",
+			Value: playstore.DefaultPackageName,
+		},
+	},
+}
+
+func run(ctx context.Context, cmd *cli.Command) error {
+	pubspecPath := cmd.String(flagPath)
+
+	sources := cmd.StringSlice(flagSources)
+	for _, source := range sources {
+		if !slices.Contains(allSources, source) {
+			return fmt.Errorf("invalid source: %s (valid sources: %s)", source, strings.Join(allSources, ", "))
+		}
+	}
+
+	current, err := pubspec.ReadVersion(pubspecPath)
+	if err != nil {
+		return err
+	}
+
+	result := checkResult{File: pubspecPath, Version: current.String(), OK: true}
+	for _, source := range sources {
+		logger.Info("checking version against source", "source", source, "version", current)
+
+		var checked sourceResult
+		switch source {
+		case sourceCloudSQL:
+			checked = checkCloudSQL(*current)
+		case sourcePlayStore:
+			checked = checkPlayStore(cmd, *current)
+		case sourceQATag:
+			checked = checkQATag(cmd, *current)
+		}
+
+		result.OK = result.OK && checked.Status == statusOK
+		result.Sources = append(result.Sources, checked)
+	}
+
+	if err := output.Print(result, func(w io.Writer) error {
+		fmt.Fprintf(w, "%s version: %s\n\n", result.File, result.Version)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SOURCE\tLATEST\tSTATUS\tMESSAGE")
+		for _, source := range result.Sources {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", source.Source, source.Latest, source.Status, source.Message)
+		}
+		return tw.Flush()
+	}); err != nil {
+		return err
+	}
+
+	if !result.OK {
+		failed := []string{}
+		for _, source := range result.Sources {
+			if source.Status != statusOK {
+				failed = append(failed, fmt.Sprintf("%s (%s)", source.Source, source.Message))
+			}
+		}
+		return fmt.Errorf("version %s failed the release check: %s", result.Version, strings.Join(failed, "; "))
+	}
+
+	return nil
+}
+
+func checkCloudSQL(current version.Version) sourceResult {
+	result := sourceResult{Source: sourceCloudSQL}
+
+	db, err := cloudsql.ConnectWithConnector()
+	if err != nil {
+		return withError(result, fmt.Errorf("connecting to cloud sql: %w", err))
+	}
+	defer db.Close()
+
+	latest, err := release.FetchLatestRelease(db)
+	if errors.Is(err, sql.ErrNoRows) {
+		result.Status = statusOK
+		result.Message = "no releases recorded"
+		return result
+	}
+	if err != nil {
+		return withError(result, fmt.Errorf("fetching latest release: %w", err))
+	}
+
+	result.Latest = latest.Version.String()
+	return compareVersions(result, current, latest.Version)
+}
+
+// checkPlayStore compares the pubspec build number with the highest version
+// code, as the build number is used as the Android version code.
+func checkPlayStore(cmd *cli.Command, current version.Version) sourceResult {
+	result := sourceResult{Source: sourcePlayStore}
+
+	credentialsPath, err := core.GetRequiredString(cmd, flagCredentialsPath)
+	if err != nil {
+		return withError(result, err)
+	}
+	credentials, err := os.ReadFile(credentialsPath)
+	if err != nil {
+		return withError(result, fmt.Errorf("reading credentials file: %w", err))
+	}
+
+	latest, err := playstore.GetVersionFromLatestRelease(credentials, core.GetString(cmd, flagPlayPackage))
+	if err != nil {
+		return withError(result, fmt.Errorf("fetching latest version code: %w", err))
+	}
+
+	result.Latest = fmt.Sprint(latest)
+	if int64(current.Build) <= latest {
+		result.Status = statusConflict
+		result.Message = fmt.Sprintf("build number %d is not greater than version code %d", current.Build, latest)
+		return result
+	}
+
+	result.Status = statusOK
+	return result
+}
+
+func checkQATag(cmd *cli.Command, current version.Version) sourceResult {
+	result := sourceResult{Source: sourceQATag}
+
+	backend := core.RepositoryBackend(cmd, git.BackendGit)
+	if cmd.Bool(flagApi) {
+		backend = git.BackendGitlab
+	}
+	repo, err := core.NewRepository(cmd, backend)
+	if err != nil {
+		return withError(result, err)
+	}
+
+	channels, err := tagchannel.Load(core.Config())
+	if err != nil {
+		return withError(result, fmt.Errorf("loading tag channels: %w", err))
+	}
+	channel, err := tagchannel.Find(channels, tagchannel.QA)
+	if err != nil {
+		return withError(result, err)
+	}
+
+	tags, err := repo.ListTags(channel.Pattern())
+	if err != nil {
+		return withError(result, err)
+	}
+
+	versions := []version.Version{}
+	for _, tag := range tags {
+		v, err := channel.Parse(tag)
+		if err != nil {
+			logger.Warn("skipping tag with an invalid version", "tag", tag, "error", err)
+			continue
+		}
+		versions = append(versions, *v)
+	}
+
+	latest := version.Max(versions...)
+	if latest == nil {
+		result.Status = statusOK
+		result.Message = "no QA tags found"
+		return result
+	}
+
+	result.Latest = channel.TagName(*latest)
+	return compareVersions(result, current, *latest)
+}
+
+func compareVersions(result sourceResult, current, latest version.Version) sourceResult {
+	if !latest.Less(current) {
+		result.Status = statusConflict
+		result.Message = fmt.Sprintf("%s is not greater than %s", current, latest)
+		return result
+	}
+
+	result.Status = statusOK
+	return result
+}
+
+func withError(result sourceResult, err error) sourceResult {
+	logger.Error("failed to check source", "source", result.Source, "error", err)
+	result.Status = statusError
+	result.Message = err.Error()
+	return result
+}
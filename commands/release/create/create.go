@@ -2,19 +2,20 @@ package create
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
-	"log/slog"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/urfave/cli/v3"
-	gitlab "gitlab.com/gitlab-org/api/client-go"
-	"ulist.app/ult/internal/assignee"
 	cloudsql "ulist.app/ult/internal/cloud_sql"
 	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/dryrun"
 	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/issuekey"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/pubspec"
 	"ulist.app/ult/internal/release"
 	"ulist.app/ult/internal/version"
 )
@@ -23,6 +24,7 @@ const (
 	flagLatest         = "latest"
 	flagFromCommit     = "from-commit"
 	flagIssueTrackerID = "issue"
+	flagMRTitle        = "merge-request-title"
 	flagBranch         = "branch"
 	flagVersion        = "version"
 	flagApi            = "api"
@@ -30,7 +32,7 @@ const (
 )
 
 var (
-	logger = slog.Default().WithGroup("bump_command")
+	logger = logging.New("create_command")
 )
 
 var fromCommitCmd = cli.Command{
@@ -49,11 +51,16 @@ var fromCommitCmd = cli.Command{
 			Aliases:  []string{"b"},
 			Required: true,
 		},
-		&cli.IntFlag{
+		&cli.StringFlag{
 			Name:    flagIssueTrackerID,
-			Usage:   "issue tracker ID for this release (if omitted, parsed from branch name)",
+			Usage:   "issue key for this release, e.g. ULT-42, a number belongs to the first project (if omitted, extracted from the branch name, commit trailers and merge request titles)",
 			Aliases: []string{"i"},
 		},
+		&cli.StringFlag{
+			Name:    flagMRTitle,
+			Usage:   "title of the merge request of the commit to extract the issue key from (with the gitlab backend defaults to the merge requests of the commit)",
+			Sources: cli.EnvVars("CI_MERGE_REQUEST_TITLE"),
+		},
 		&cli.StringFlag{
 			Name:    flagVersion,
 			Usage:   "version string for this release (if omitted, read from pubspec.yaml)",
@@ -61,7 +68,7 @@ var fromCommitCmd = cli.Command{
 		},
 		&cli.BoolFlag{
 			Name:    flagApi,
-			Usage:   "fetch commit metadata from the GitLab API instead of local git",
+			Usage:   "fetch commit metadata from the GitLab API instead of local git (same as --backend gitlab)",
 			Aliases: []string{"a"},
 		},
 		&cli.BoolFlag{
@@ -92,66 +99,37 @@ func runFromCommit(ctx context.Context, cmd *cli.Command) error {
 	var ver *version.Version
 	var err error
 	branch := cmd.String(flagBranch)
-	issueTrackerID := cmd.Int(flagIssueTrackerID)
 	versionStr := cmd.String(flagVersion)
 	skipDuplicates := cmd.Bool(flagSkipDupes)
 	if len(versionStr) == 0 {
 		logger.Info("no version was passed as argument, will try to fetch from pubspec file")
-		contents, err := os.ReadFile("pubspec.yaml")
+		ver, err = pubspec.ReadVersion(pubspec.DefaultPath)
 		if err != nil {
-			return fmt.Errorf("reading pubspec.yaml: %w", err)
+			return fmt.Errorf("reading version from pubspec.yaml: %w", err)
 		}
-
-		lines := strings.Split(string(contents), "\n")
-		logger.Debug("Read pubspec.yaml", "lineCount", len(lines))
-
-		ver, _, err = version.FetchFromLines(lines)
+	} else {
+		ver, err = version.Parse(versionStr)
 		if err != nil {
 			return fmt.Errorf("parsing version: %w", err)
 		}
 	}
-	api := cmd.Bool(flagApi)
-
-	var commit *git.Commit
-	if api {
-		token, err := core.GetToken(cmd)
-		if err != nil {
-			return fmt.Errorf("fetching environmental variable (project-id): %w", err)
-		}
-
-		projectId, err := core.GetProjectID(cmd)
-		if err != nil {
-			return fmt.Errorf("fetching environmental variable (token): %w", err)
-		}
-
-		repo, err := gitlab.NewClient(token)
-		if err != nil {
-			return fmt.Errorf("initializing gitlab http client: %w", err)
-		}
-
-		if issueTrackerID == 0 {
-			issueTrackerID, err = git.GetIssueNumberFromBranch(branch)
-			if err != nil {
-				return fmt.Errorf("parsing issue number from branch: %w", err)
-			}
-		}
+	backend := core.RepositoryBackend(cmd, git.BackendGit)
+	if cmd.Bool(flagApi) {
+		backend = git.BackendGitlab
+	}
+	repo, err := core.NewRepository(cmd, backend)
+	if err != nil {
+		return fmt.Errorf("opening %s repository: %w", backend, err)
+	}
 
-		gitlabCommit, _, err := repo.Commits.GetCommit(projectId, hash, &gitlab.GetCommitOptions{})
-		if err != nil {
-			return fmt.Errorf("fetching commits from gitlab projectId (%v): %w", projectId, err)
-		}
+	commit, err := repo.CommitInfo(hash)
+	if err != nil {
+		return fmt.Errorf("fetching commit from %s repository: %w", backend, err)
+	}
 
-		commit = &git.Commit{
-			Assignee: assignee.Assignee{Name: gitlabCommit.AuthorName, Email: gitlabCommit.AuthorEmail},
-			Hash:     hash,
-			Date:     *gitlabCommit.CommittedDate,
-			Message:  gitlabCommit.Message,
-		}
-	} else {
-		commit, err = getCommitFromGitCommand(hash)
-		if err != nil {
-			return fmt.Errorf("fetching commit from local git repo: %w", err)
-		}
+	issueKey, err := resolveIssueKey(cmd, repo, branch, commit)
+	if err != nil {
+		return err
 	}
 
 	releaseEn := &release.Release{
@@ -159,14 +137,19 @@ func runFromCommit(ctx context.Context, cmd *cli.Command) error {
 		Assignee:       commit.Assignee,
 		Description:    commit.Message,
 		Commit:         commit.Hash,
-		IssueTrackerID: int(issueTrackerID),
+		IssueTrackerID: issuekey.Number(issueKey),
+		IssueKey:       issueKey,
 		Version:        *ver,
 		Date:           time.Now(),
 	}
 
-	db, err := cloudsql.ConnectWithConnector()
-	if err != nil {
-		return fmt.Errorf("not able to connect with database to create release: %w", err)
+	// a dry-run only prints the release, so no database connection is needed
+	var db *sql.DB
+	if !dryrun.Enabled() {
+		db, err = cloudsql.ConnectWithConnector()
+		if err != nil {
+			return fmt.Errorf("not able to connect with database to create release: %w", err)
+		}
 	}
 
 	err = release.SaveRelease(db, releaseEn)
@@ -182,23 +165,37 @@ func runFromCommit(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
-func getCommitFromGitCommand(hash string) (*git.Commit, error) {
-	var err error
-	var commit *git.Commit
+// resolveIssueKey returns the --issue key or the key extracted from the
+// branch, the commit trailers and the merge request titles. A release
+// without issue is only a warning.
+func resolveIssueKey(cmd *cli.Command, repo git.Repository, branch string, commit *git.Commit) (string, error) {
+	extractor, err := issuekey.Load(core.Config())
+	if err != nil {
+		return "", fmt.Errorf("loading issue keys: %w", err)
+	}
 
-	if len(hash) == 0 {
-		commit, err = git.GetLatestCommitInfo()
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		commit, err = git.GetCommitInfo(hash)
+	if cmd.IsSet(flagIssueTrackerID) {
+		return extractor.Normalize(cmd.String(flagIssueTrackerID))
+	}
+
+	input := issuekey.Input{Branch: branch, Trailers: commit.Trailers}
+	if title := cmd.String(flagMRTitle); len(title) > 0 {
+		input.MergeRequestTitles = []string{title}
+	} else if mrs, ok := repo.(git.MergeRequests); ok {
+		titles, err := mrs.MergeRequestTitles(commit.Hash)
 		if err != nil {
-			return nil, err
+			logger.Warn("Not able to fetch merge requests of commit", "error", err)
 		}
+		input.MergeRequestTitles = titles
 	}
 
-	return commit, nil
+	match, ok := extractor.Extract(input)
+	if !ok {
+		logger.Warn("Release without issue", "branch", branch, "commit", commit.Hash)
+		return "", nil
+	}
+	logger.Info("Found issue key", "key", match.Key, "source", match.Source)
+	return match.Key, nil
 }
 
 func run(ctx context.Context, cmd *cli.Command) error {
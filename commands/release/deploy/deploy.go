@@ -4,52 +4,60 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log/slog"
+	"io"
 	"os"
 	"strings"
 
-	"github.com/charmbracelet/log"
 	"github.com/urfave/cli/v3"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/firebaseappdistribution/v1"
 	"google.golang.org/api/option"
 	appdistribution "ulist.app/ult/internal/app_distribution"
+	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/output"
 )
 
 const (
-	flagAppId        = "app"
-	flagGroups       = "groups"
-	flagJsonKey      = "json-key"
+	flagAppId        = core.AppFlag
+	flagGroups       = core.GroupsFlag
+	flagJsonKey      = core.JSONKeyFlag
 	flagReleaseNotes = "release-notes"
 )
 
 var (
-	// logger = slog.Default().WithGroup("deploy_command")
-	logger = log.NewWithOptions(os.Stdout, log.Options{
-		Level:           log.DebugLevel,
-		ReportTimestamp: true,
-	})
+	logger = logging.New("deploy_command")
 )
 
+// deployResult is the output schema of a build distributed through Firebase App Distribution.
+type deployResult struct {
+	App                string   `json:"app" yaml:"app"`
+	Release            string   `json:"release" yaml:"release"`
+	DisplayVersion     string   `json:"display_version" yaml:"display_version"`
+	BuildVersion       string   `json:"build_version" yaml:"build_version"`
+	FirebaseConsoleURI string   `json:"firebase_console_uri" yaml:"firebase_console_uri"`
+	TestingURI         string   `json:"testing_uri" yaml:"testing_uri"`
+	Groups             []string `json:"groups" yaml:"groups"`
+	DryRun             bool     `json:"dry_run" yaml:"dry_run"`
+}
+
 var Cmd = cli.Command{
 	Name:   "deploy",
 	Usage:  "upload a build to Firebase App Distribution and distribute it to tester groups",
 	Action: run,
 	Flags: []cli.Flag{
 		&cli.StringFlag{
-			Name:     flagAppId,
-			Usage:    "Firebase app ID (format: 1:project_number:platform:hash)",
-			Required: true,
+			Name:  flagAppId,
+			Usage: "Firebase app ID (format: 1:project_number:platform:hash)",
 		},
 		&cli.StringSliceFlag{
-			Name:     flagGroups,
-			Usage:    "tester group aliases to distribute the release to",
-			Required: true,
+			Name:  flagGroups,
+			Usage: "tester group aliases to distribute the release to",
 		},
 		&cli.StringFlag{
-			Name:     flagJsonKey,
-			Usage:    "path to the Firebase service account credentials JSON file",
-			Required: true,
+			Name:  flagJsonKey,
+			Usage: "path to the Firebase service account credentials JSON file",
 		},
 		&cli.StringFlag{
 			Name:  flagReleaseNotes,
@@ -59,11 +67,17 @@ var Cmd = cli.Command{
 }
 
 func run(ctx context.Context, cmd *cli.Command) error {
-	jsonKeyPath := cmd.String(flagJsonKey)
-	appID := strings.Trim(cmd.String(flagAppId), "\"")
-	groups := cmd.StringSlice(flagGroups)
+	jsonKeyPath, err := core.GetRequiredString(cmd, flagJsonKey)
+	if err != nil {
+		return err
+	}
+	appID, err := core.GetRequiredString(cmd, flagAppId)
+	if err != nil {
+		return err
+	}
+	appID = strings.Trim(appID, "\"")
+	groups := core.GetStringSlice(cmd, flagGroups)
 	notes := cmd.String(flagReleaseNotes)
-	verbose := cmd.Bool("verbose")
 
 	logger.Info("Starting deploy command",
 		"build file type", cmd.Args().First(),
@@ -107,10 +121,7 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	opts := []option.ClientOption{
 		option.WithHTTPClient(jwt.Client(ctx)),
 		option.WithScopes(firebaseappdistribution.CloudPlatformScope),
-	}
-	if verbose {
-		l := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
-		opts = append(opts, option.WithLogger(l))
+		option.WithLogger(logging.New("firebase")),
 	}
 	logger.Info("creating firebase app distribution service")
 	service, err := firebaseappdistribution.NewService(ctx, opts...)
@@ -131,9 +142,13 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	release, err := appdistribution.PollOperation(operation.Name, service)
-	if err != nil {
-		return err
+	// nothing is uploaded on a dry-run, so there is no operation to poll
+	release := &appdistribution.Release{Name: app + "/releases/<new release>"}
+	if !dryrun.Enabled() {
+		release, err = appdistribution.PollOperation(operation.Name, service)
+		if err != nil {
+			return err
+		}
 	}
 	logger.Debug("RELEASE TEST", "create time", release.CreateTime, "build version", release.BuildVersion, "binary uri", release.BinaryDownloadUri)
 
@@ -150,5 +165,22 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	}
 
 	logger.Info("successfully created and distributed release")
-	return nil
+	result := deployResult{
+		App:                app,
+		Release:            release.Name,
+		DisplayVersion:     release.DisplayVersion,
+		BuildVersion:       release.BuildVersion,
+		FirebaseConsoleURI: release.FirebaseConsoleUri,
+		TestingURI:         release.TestingUri,
+		Groups:             groups,
+		DryRun:             dryrun.Enabled(),
+	}
+	return output.Print(result, func(w io.Writer) error {
+		if result.DryRun {
+			return nil
+		}
+		_, err := fmt.Fprintf(w, "Distributed release %s (%s+%s) to groups: %s\n",
+			result.Release, result.DisplayVersion, result.BuildVersion, strings.Join(result.Groups, ", "))
+		return err
+	})
 }
@@ -6,32 +6,46 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/urfave/cli/v3"
+	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/output"
 	"ulist.app/ult/internal/playstore"
 )
 
 const (
-	flagCredentialsPath = "credentials"
+	flagCredentialsPath = core.CredentialsFlag
 )
 
+// releaseResult is the output schema of a single Play Store release.
+type releaseResult struct {
+	Name         string  `json:"name" yaml:"name"`
+	Status       string  `json:"status" yaml:"status"`
+	VersionCodes []int64 `json:"version_codes" yaml:"version_codes"`
+}
+
 var Cmd = cli.Command{
 	Name:   "list",
 	Usage:  "list all releases in the Play Store production track",
 	Action: run,
 	Flags: []cli.Flag{
 		&cli.StringFlag{
-			Name:     flagCredentialsPath,
-			Usage:    "path to the Google Play Store service account credentials JSON file",
-			Required: true,
+			Name:  flagCredentialsPath,
+			Usage: "path to the Google Play Store service account credentials JSON file",
 		},
 	},
 }
 
 func run(ctx context.Context, cmd *cli.Command) error {
-	credentials, err := os.ReadFile(cmd.String(flagCredentialsPath))
+	credentialsPath, err := core.GetRequiredString(cmd, flagCredentialsPath)
+	if err != nil {
+		return err
+	}
+
+	credentials, err := os.ReadFile(credentialsPath)
 	if err != nil {
 		return fmt.Errorf("reading credentials file: %w", err)
 	}
@@ -44,14 +58,24 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		return errors.New("no releases found in the production track")
 	}
 
+	results := make([]releaseResult, 0, len(releases))
 	for _, release := range releases {
-		codes := make([]string, 0, len(release.VersionCodes))
-		for _, code := range release.VersionCodes {
-			codes = append(codes, fmt.Sprint(code))
-		}
-		fmt.Printf("Latest PROD Release: %s+%s\n",
-			release.Name, strings.Join(codes, ", "))
+		results = append(results, releaseResult{
+			Name:         release.Name,
+			Status:       release.Status,
+			VersionCodes: release.VersionCodes,
+		})
 	}
 
-	return nil
+	return output.Print(results, func(w io.Writer) error {
+		for _, release := range results {
+			codes := make([]string, 0, len(release.VersionCodes))
+			for _, code := range release.VersionCodes {
+				codes = append(codes, fmt.Sprint(code))
+			}
+			fmt.Fprintf(w, "Latest PROD Release: %s+%s\n",
+				release.Name, strings.Join(codes, ", "))
+		}
+		return nil
+	})
 }
@@ -5,10 +5,12 @@ package release_command
 import (
 	"github.com/urfave/cli/v3"
 	"ulist.app/ult/commands/release/bump"
+	"ulist.app/ult/commands/release/check"
 	"ulist.app/ult/commands/release/create"
 	"ulist.app/ult/commands/release/deploy"
 	"ulist.app/ult/commands/release/list"
 	"ulist.app/ult/commands/release/set_version"
+	"ulist.app/ult/commands/release/sync_version"
 )
 
 const (
@@ -20,9 +22,11 @@ var Cmd = cli.Command{
 	Usage: "manage app releases: bump version, create release records, and deploy builds",
 	Commands: []*cli.Command{
 		&bump.Cmd,
+		&check.Cmd,
 		&create.Cmd,
 		&deploy.Cmd,
 		&list.Cmd,
 		&set_version.Cmd,
+		&sync_version.Cmd,
 	},
 }
@@ -3,15 +3,20 @@ package set_version
 import (
 	"context"
 	"fmt"
-	"log/slog"
-	"os"
+	"io"
 	"strings"
 
 	"github.com/urfave/cli/v3"
-	gitlab "gitlab.com/gitlab-org/api/client-go"
 	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/dryrun"
 	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/output"
+	"ulist.app/ult/internal/pubspec"
+	"ulist.app/ult/internal/remote"
+	"ulist.app/ult/internal/tagchannel"
 	"ulist.app/ult/internal/version"
+	"ulist.app/ult/internal/versionsync"
 )
 
 const (
@@ -19,12 +24,30 @@ const (
 	flagFromTag = "from-tag"
 	flagApi     = "api"
 	flagHash    = "hash"
+	flagRemote  = "remote"
+	flagRef     = "ref"
+	flagMessage = "commit-message"
+	flagChannel = "channel"
 )
 
+// defaultCommitMessage is the message of the commit created by --remote.
+const defaultCommitMessage = "set version {version} [skip ci]"
+
 var (
-	logger = slog.Default().WithGroup("set_version_command")
+	logger = logging.New("set_version_command")
 )
 
+// setVersionResult is the output schema of a version written into pubspec.yaml.
+// Tag holds the git tag the version was derived from when using --from-tag,
+// Commit the commit created on the remote branch when using --remote.
+type setVersionResult struct {
+	File    string `json:"file" yaml:"file"`
+	Version string `json:"version" yaml:"version"`
+	Tag     string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Commit  string `json:"commit,omitempty" yaml:"commit,omitempty"`
+	DryRun  bool   `json:"dry_run" yaml:"dry_run"`
+}
+
 var Cmd = cli.Command{
 	Name:   "set-version",
 	Usage:  "write a specific version string into pubspec.yaml",
@@ -38,13 +61,29 @@ var Cmd = cli.Command{
 			Name:  flagFromTag,
 			Usage: "derive the version from the git tag on the target commit instead of a positional argument",
 		},
+		&cli.StringFlag{
+			Name:  flagChannel,
+			Usage: "tag channel whose template the tag is parsed with (used with --from-tag; defaults to the first configured channel with a tag on the commit)",
+		},
 		&cli.BoolFlag{
 			Name:  flagApi,
-			Usage: "fetch the tag via the GitLab API (requires --token and --project-id; default uses local git)",
+			Usage: "fetch the tag via the GitLab API (requires --token and --project-id; same as --backend gitlab)",
 		},
 		&cli.StringFlag{
 			Name:  flagHash,
-			Usage: "commit hash to look up the tag for (used with --from-tag; defaults to HEAD)",
+			Usage: "commit hash to look up the tag for (used with --from-tag; defaults to the head of the current branch, or of --ref with --remote)",
+		},
+		&cli.BoolFlag{
+			Name:  flagRemote,
+			Usage: "read pubspec.yaml and the version sync targets from --ref through the GitLab API and commit the change back, no checkout needed (requires --token and --project-id; implies --api)",
+		},
+		&cli.StringFlag{
+			Name:  flagRef,
+			Usage: "branch read and committed to by --remote (defaults to --branch)",
+		},
+		&cli.StringFlag{
+			Name:  flagMessage,
+			Usage: "message of the commit created by --remote, supports the {version} placeholders (defaults to '" + defaultCommitMessage + "')",
 		},
 	},
 }
@@ -52,14 +91,26 @@ var Cmd = cli.Command{
 func run(ctx context.Context, cmd *cli.Command) error {
 	versionStr := cmd.Args().First()
 	useTagAsVersion := cmd.Bool(flagFromTag)
-	fetchTagFromGitlab := cmd.Bool(flagApi)
+	useRemote := cmd.Bool(flagRemote)
+	fetchTagFromGitlab := cmd.Bool(flagApi) || useRemote
 	commitHash := cmd.String(flagHash)
-	if len(commitHash) == 0 {
-		commitHash = "HEAD"
+
+	var repo *git.Gitlab
+	ref := ""
+	if useRemote {
+		var err error
+		repo, err = newRemoteRepository(cmd)
+		if err != nil {
+			return err
+		}
+		if ref, err = repo.CurrentBranch(); err != nil {
+			return err
+		}
 	}
+
 	pubspecPath := cmd.String(flagPath)
 	if len(pubspecPath) == 0 {
-		pubspecPath = "pubspec.yaml"
+		pubspecPath = pubspec.DefaultPath
 	}
 
 	logger.Debug("Starting set version command",
@@ -69,124 +120,153 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		"hash", commitHash,
 	)
 
-	token, err := core.GetToken(cmd)
-	if err != nil {
-		return err
-	}
-	projectId, err := core.GetProjectID(cmd)
-	if err != nil {
-		return err
-	}
-
+	var (
+		tag        string
+		newVersion *version.Version
+		err        error
+	)
 	if len(versionStr) == 0 {
 		if !useTagAsVersion {
 			return fmt.Errorf("you need to provide version as positional argument (usage: ult release set-version 2000.100.10+01) or use --%s\n", flagFromTag)
 		}
 
-		var tag string
-		var err error
+		backend := core.RepositoryBackend(cmd, git.BackendGit)
 		if fetchTagFromGitlab {
-			tag, err = tagFromGitlab(commitHash, token, projectId)
-			if err != nil {
-				return err
-			}
-		} else {
-			tag, err = tagFromGit(commitHash)
+			backend = git.BackendGitlab
+		}
+		gitRepo, err := core.NewRepository(cmd, backend)
+		if err != nil {
+			return err
+		}
+
+		if len(commitHash) == 0 {
+			// the head of --ref, or of the current branch
+			commit, err := gitRepo.CommitInfo(ref)
 			if err != nil {
-				return err
+				return fmt.Errorf("fetching head commit: %w", err)
 			}
+			commitHash = commit.Hash
 		}
 
-		if len(tag) == 0 {
-			return fmt.Errorf("no tag was found for the given commit hash (%s)", commitHash)
+		channels, err := tagChannels(cmd)
+		if err != nil {
+			return err
 		}
-
-		if !strings.Contains(tag, "+") {
-			return fmt.Errorf("%s is an invalid QA version tag, it should contain '+' sign", tag)
+		tag, newVersion, err = versionFromTag(gitRepo, commitHash, channels)
+		if err != nil {
+			return err
 		}
-
-		versionStr = strings.Replace(tag, "QA-v", "", 1)
-		versionStr = strings.TrimSpace(versionStr)
-	}
-
-	newVersion, err := version.Parse(versionStr)
-	if err != nil {
+	} else if newVersion, err = version.Parse(versionStr); err != nil {
 		return err
 	}
 
-	contents, err := os.ReadFile(pubspecPath)
+	var file *pubspec.File
+	if repo != nil {
+		file, err = remote.ReadPubspec(repo, pubspecPath)
+	} else {
+		file, err = pubspec.Read(pubspecPath)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to read pubspec.yaml: %w", err)
+		return fmt.Errorf("failed to find version string in pubspec file: %w", err)
 	}
 
-	lines := strings.Split(string(contents), "\n")
-
-	_, idx, err := version.FetchFromLines(lines)
+	targets, err := versionsync.LoadTargets(core.Config())
 	if err != nil {
-		return fmt.Errorf("failed to find version string in pubspec file: %w", err)
+		return fmt.Errorf("loading version sync targets: %w", err)
 	}
 
-	// Update version in pubspec lines
-	lines[idx] = fmt.Sprintf("version: %s", newVersion)
+	result := setVersionResult{File: pubspecPath, Version: newVersion.String(), Tag: strings.TrimSpace(tag), DryRun: dryrun.Enabled()}
 
-	// Write back to file with original permissions
-	if err := writeFile(pubspecPath, lines); err != nil {
+	if repo != nil {
+		message := cmd.String(flagMessage)
+		if len(message) == 0 {
+			message = defaultCommitMessage
+		}
+		result.Commit, err = remote.UpdateVersion(repo, file, targets, *newVersion, newVersion.Expand(message))
+		if err != nil {
+			return err
+		}
+	} else if err := versionsync.Update(file, targets, *newVersion); err != nil {
 		return err
 	}
 
-	fmt.Printf("updated pubspec.yaml with version: %s\n", newVersion)
-	return nil
+	return output.Print(result, func(w io.Writer) error {
+		if result.DryRun {
+			return nil
+		}
+		if repo != nil && len(result.Commit) == 0 {
+			_, err := fmt.Fprintf(w, "%s at %s already has version: %s\n", result.File, ref, result.Version)
+			return err
+		}
+		_, err := fmt.Fprintf(w, "updated pubspec.yaml with version: %s\n", result.Version)
+		if err == nil && len(result.Commit) > 0 {
+			_, err = fmt.Fprintf(w, "committed %s to %s\n", result.Commit, ref)
+		}
+		return err
+	})
 }
 
-// writeFile writes the provided lines back to the specified file path,
-// preserving the original file permissions. It returns an error if
-// getting file info or writing to the file fails.
-func writeFile(path string, lines []string) error {
-	fileInfo, err := os.Stat(path)
+// tagChannels returns the channel selected with --channel, or every
+// configured channel.
+func tagChannels(cmd *cli.Command) ([]tagchannel.Channel, error) {
+	channels, err := tagchannel.Load(core.Config())
 	if err != nil {
-		return fmt.Errorf("getting file info: %w", err)
+		return nil, fmt.Errorf("loading tag channels: %w", err)
 	}
 
-	content := strings.Join(lines, "\n")
-	err = os.WriteFile(path, []byte(content), fileInfo.Mode())
-	if err != nil {
-		return fmt.Errorf("writing file: %w", err)
-	}
-
-	return nil
-}
-
-func tagFromGit(hash string) (string, error) {
-	tag, err := git.GetTagFromCommit(hash)
-	if err != nil {
-		return tag, err
+	name := cmd.String(flagChannel)
+	if len(name) == 0 {
+		return channels, nil
 	}
-
-	return tag, nil
-}
-
-func tagFromGitlab(hash, token, projectId string) (string, error) {
-	var tag string
-
-	appRepo, err := gitlab.NewClient(token)
+	channel, err := tagchannel.Find(channels, name)
 	if err != nil {
-		return tag, err
+		return nil, err
 	}
-	opt := gitlab.ListTagsOptions{
-		Search: gitlab.Ptr("^QA-v"),
-	}
-	tags, _, err := appRepo.Tags.ListTags(projectId, &opt)
-	if err != nil {
-		return "", fmt.Errorf("fetching tag list from gitlab: %w", err)
+	if !channel.HasBuild() {
+		return nil, fmt.Errorf("the tags of channel %s do not hold the build number (template '%s')", channel.Name, channel.Template)
 	}
+	return []tagchannel.Channel{*channel}, nil
+}
 
-	for _, tag := range tags {
-		if !strings.Contains(tag.Commit.ID, hash) {
+// versionFromTag reads the version from the first tag of the commit that
+// matches a channel template, the channels are tried in order.
+func versionFromTag(repo git.Repository, commitHash string, channels []tagchannel.Channel) (string, *version.Version, error) {
+	names := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		names = append(names, channel.Name)
+		if !channel.HasBuild() {
 			continue
 		}
 
-		return tag.Name, nil
+		var found *version.Version
+		tag, err := repo.FindTag(commitHash, channel.Pattern(), func(tag string) bool {
+			v, err := channel.Parse(tag)
+			if err != nil {
+				logger.Warn("skipping tag with an invalid version", "tag", tag, "channel", channel.Name, "error", err)
+				return false
+			}
+			found = v
+			return true
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		if found != nil {
+			logger.Info("found version tag", "tag", tag, "channel", channel.Name, "version", found)
+			return tag, found, nil
+		}
 	}
+	return "", nil, fmt.Errorf("no tag of the channels %s was found for the given commit hash (%s)", strings.Join(names, ", "), commitHash)
+}
 
-	return tag, nil
+// newRemoteRepository returns the branch of the GitLab project used by --remote.
+func newRemoteRepository(cmd *cli.Command) (*git.Gitlab, error) {
+	ref := cmd.String(flagRef)
+	if len(ref) == 0 {
+		ref = core.GetString(cmd, core.BranchFlag)
+	}
+	if len(ref) == 0 {
+		return nil, fmt.Errorf("when using '--%s' flag the branch must be provided '--%s=main'", flagRemote, flagRef)
+	}
+	return remote.Open(cmd, ref)
 }
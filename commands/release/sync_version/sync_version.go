@@ -0,0 +1,124 @@
+// Package sync_version provides the command that copies the pubspec.yaml
+// version into the native project files configured as version sync targets.
+package sync_version
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/output"
+	"ulist.app/ult/internal/pubspec"
+	"ulist.app/ult/internal/versionsync"
+)
+
+const (
+	flagPath  = "path"
+	flagCheck = "check"
+)
+
+var (
+	logger = logging.New("sync_version_command")
+)
+
+// syncResult is the output schema of a version sync.
+type syncResult struct {
+	Version string         `json:"version" yaml:"version"`
+	Targets []targetResult `json:"targets" yaml:"targets"`
+	Check   bool           `json:"check" yaml:"check"`
+	DryRun  bool           `json:"dry_run" yaml:"dry_run"`
+}
+
+// targetResult is the state of a single sync target. Changed is true when
+// the target was updated, or drifted from pubspec.yaml when using --check.
+type targetResult struct {
+	Path    string `json:"path" yaml:"path"`
+	Kind    string `json:"kind" yaml:"kind"`
+	Changed bool   `json:"changed" yaml:"changed"`
+}
+
+var Cmd = cli.Command{
+	Name:   "sync-version",
+	Usage:  "write the pubspec.yaml version into the version sync targets configured in .ult.yaml (build.gradle, Info.plist, xcconfig, version.g.dart)",
+	Action: run,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  flagPath,
+			Usage: "path to the pubspec.yaml file",
+			Value: pubspec.DefaultPath,
+		},
+		&cli.BoolFlag{
+			Name:  flagCheck,
+			Usage: "do not write anything, fail if any target differs from the pubspec.yaml version",
+		},
+	},
+}
+
+func run(ctx context.Context, cmd *cli.Command) error {
+	targets, err := versionsync.LoadTargets(core.Config())
+	if err != nil {
+		return fmt.Errorf("loading version sync targets: %w", err)
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no version sync targets configured, add them to '%s' in .ult.yaml", versionsync.ConfigKey)
+	}
+
+	current, err := pubspec.ReadVersion(cmd.String(flagPath))
+	if err != nil {
+		return err
+	}
+
+	changes, err := versionsync.Plan(targets, *current)
+	if err != nil {
+		return err
+	}
+
+	check := cmd.Bool(flagCheck)
+	result := syncResult{Version: current.String(), Check: check, DryRun: dryrun.Enabled()}
+	drifted := []string{}
+	for i, change := range changes {
+		result.Targets = append(result.Targets, targetResult{
+			Path:    change.Path,
+			Kind:    string(targets[i].Kind),
+			Changed: change.Changed(),
+		})
+		if change.Changed() {
+			drifted = append(drifted, change.Path)
+		}
+	}
+	logger.Debug("planned version sync", "version", result.Version, "changed", drifted)
+
+	if !check {
+		if err := versionsync.Apply(changes); err != nil {
+			return err
+		}
+	}
+
+	if err := output.Print(result, func(w io.Writer) error {
+		for _, target := range result.Targets {
+			status := "up to date"
+			switch {
+			case target.Changed && check:
+				status = "out of date"
+			case target.Changed && !result.DryRun:
+				status = "updated"
+			case target.Changed:
+				continue
+			}
+			fmt.Fprintf(w, "%s: %s\n", target.Path, status)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if check && len(drifted) > 0 {
+		return fmt.Errorf("version of %s differs from pubspec.yaml (%s), run 'ult release sync-version'", strings.Join(drifted, ", "), result.Version)
+	}
+	return nil
+}
@@ -3,13 +3,14 @@ package secrets_command
 import (
 	"context"
 	"fmt"
-	"log/slog"
-	"os"
+	"io"
 	"path"
 	"path/filepath"
 
 	"github.com/urfave/cli/v3"
 	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/output"
 	"ulist.app/ult/internal/secrets"
 )
 
@@ -25,10 +26,7 @@ const (
 )
 
 var (
-	lvl    = new(slog.LevelVar)
-	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-		Level: lvl,
-	})).WithGroup("secrets_command")
+	logger = logging.New("secrets_command")
 )
 
 var Cmd = cli.Command{
@@ -55,7 +53,7 @@ var Cmd = cli.Command{
 		},
 		{
 			Name:   "delete",
-			Usage:  "delete a secure file from the project",
+			Usage:  "delete a secure file from the project, the last one listed when several have the name",
 			Action: deleteSecureFileCommand,
 			Flags: []cli.Flag{
 				&cli.BoolFlag{
@@ -72,7 +70,7 @@ var Cmd = cli.Command{
 		},
 		{
 			Name:   "update",
-			Usage:  "replace the existing secrets archive with a new one",
+			Usage:  "replace the existing secrets archive with a new one (the last one listed when several have the name)",
 			Action: updateSecureFileCommand,
 			Flags: []cli.Flag{
 				&cli.StringFlag{
@@ -86,17 +84,8 @@ var Cmd = cli.Command{
 	},
 }
 
-func setLoggingVerbosity(verbose bool) {
-	if verbose {
-		lvl.Set(slog.LevelInfo)
-	} else {
-		lvl.Set(slog.LevelError)
-	}
-}
-
 func listSecureFilesCommand(ctx context.Context, cmd *cli.Command) error {
-	setLoggingVerbosity(cmd.Bool(core.VerboseFlag))
-	token, err := core.GetToken(cmd)
+	appRepo, err := core.NewGitlabClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -110,21 +99,26 @@ func listSecureFilesCommand(ctx context.Context, cmd *cli.Command) error {
 	showOnlyId := cmd.Bool(flagId)
 	logger.Info("Fetching secure files", "with name", targetName, "show only id", showOnlyId)
 
-	files, _, err := secrets.FetchAll(projectId, token)
+	files, err := secrets.FetchAll(appRepo, projectId)
 	if err != nil {
 		return err
 	}
 
-	for _, file := range files {
-		secrets.PrintSecureFile(file, showOnlyId, targetName)
-	}
-
-	return nil
+	results := secrets.FilterFiles(files, targetName)
+	return output.Print(results, func(w io.Writer) error {
+		for _, file := range results {
+			if showOnlyId {
+				fmt.Fprintln(w, file.ID)
+			} else {
+				fmt.Fprintln(w, file)
+			}
+		}
+		return nil
+	})
 }
 
 func deleteSecureFileCommand(ctx context.Context, cmd *cli.Command) error {
-	setLoggingVerbosity(cmd.Bool(core.VerboseFlag))
-	token, err := core.GetToken(cmd)
+	appRepo, err := core.NewGitlabClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -138,13 +132,11 @@ func deleteSecureFileCommand(ctx context.Context, cmd *cli.Command) error {
 	showOnlyId := cmd.Bool(flagId)
 	logger.Info("Fetching secure files", "with name", targetName, "show only id", showOnlyId)
 
-	files, appRepo, err := secrets.FetchAll(projectId, token)
+	file, err := secrets.FindByName(appRepo, projectId, targetName)
 	if err != nil {
 		return err
 	}
-
-	foundFile, file := secrets.GetSecureFile(files, targetName)
-	if !foundFile {
+	if file == nil {
 		return fmt.Errorf("No secure file was found with given name: %s", targetName)
 	}
 
@@ -157,8 +149,7 @@ func deleteSecureFileCommand(ctx context.Context, cmd *cli.Command) error {
 }
 
 func updateSecureFileCommand(ctx context.Context, cmd *cli.Command) error {
-	setLoggingVerbosity(cmd.Bool(core.VerboseFlag))
-	token, err := core.GetToken(cmd)
+	appRepo, err := core.NewGitlabClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -182,14 +173,12 @@ func updateSecureFileCommand(ctx context.Context, cmd *cli.Command) error {
 	}
 	logger.Info("Using absolute path for archive upload", "path", path)
 
-	files, appRepo, err := secrets.FetchAll(projectId, token)
+	logger.Info("Looking for secure file", "name", defaultSecretsFileName)
+	file, err := secrets.FindByName(appRepo, projectId, defaultSecretsFileName)
 	if err != nil {
 		return err
 	}
-
-	logger.Info("Looking for secure file", "name", defaultSecretsFileName)
-	foundFile, file := secrets.GetSecureFile(files, defaultSecretsFileName)
-	if foundFile {
+	if file != nil {
 		logger.Info("Found secure file", "id", file.ID, "name", defaultSecretsFileName)
 
 		logger.Info("Deleting existing secure file", "id", file.ID)
@@ -0,0 +1,168 @@
+package commit_command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/output"
+	"ulist.app/ult/internal/tagchannel"
+	"ulist.app/ult/internal/version"
+)
+
+const (
+	flagMinVersion = "min-version"
+	flagMaxVersion = "max-version"
+	flagReverse    = "reverse"
+)
+
+// listedTag is the output schema of a listed tag.
+type listedTag struct {
+	Name    string    `json:"name" yaml:"name"`
+	Channel string    `json:"channel" yaml:"channel"`
+	Version string    `json:"version" yaml:"version"`
+	Commit  string    `json:"commit" yaml:"commit"`
+	Date    time.Time `json:"date" yaml:"date"`
+}
+
+var listCmd = cli.Command{
+	Name:   "list",
+	Usage:  "list the tags of the tag channels sorted by version, the oldest first",
+	Action: runList,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  flagChannel,
+			Usage: "only list the tags of the channel, e.g. 'qa' (defaults to every configured channel)",
+		},
+		&cli.StringFlag{
+			Name:  flagMinVersion,
+			Usage: "only list versions greater than or equal to this one, without a build number every build of it is included",
+		},
+		&cli.StringFlag{
+			Name:  flagMaxVersion,
+			Usage: "only list versions lower than or equal to this one, without a build number every build of it is included",
+		},
+		&cli.BoolFlag{
+			Name:  flagReverse,
+			Usage: "list the newest version first",
+		},
+	},
+}
+
+func runList(ctx context.Context, cmd *cli.Command) error {
+	repo, err := core.NewRepository(cmd, core.RepositoryBackend(cmd, git.BackendGit))
+	if err != nil {
+		return err
+	}
+	channels, err := selectChannels(cmd.String(flagChannel))
+	if err != nil {
+		return err
+	}
+	minBound, err := parseVersionBound(cmd.String(flagMinVersion))
+	if err != nil {
+		return fmt.Errorf("invalid '--%s': %w", flagMinVersion, err)
+	}
+	maxBound, err := parseVersionBound(cmd.String(flagMaxVersion))
+	if err != nil {
+		return fmt.Errorf("invalid '--%s': %w", flagMaxVersion, err)
+	}
+
+	tags, err := tagchannel.List(repo, channels)
+	if err != nil {
+		return err
+	}
+	if cmd.Bool(flagReverse) {
+		slices.Reverse(tags)
+	}
+
+	results := []listedTag{}
+	for _, tag := range tags {
+		if !minBound.allows(tag.Version, 1) || !maxBound.allows(tag.Version, -1) {
+			continue
+		}
+		results = append(results, listedTag{
+			Name:    tag.Name,
+			Channel: tag.Channel,
+			Version: tag.Version.String(),
+			Commit:  tag.Commit,
+			Date:    tag.Date,
+		})
+	}
+
+	return output.Print(results, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TAG\tCHANNEL\tCOMMIT\tDATE")
+		for _, result := range results {
+			fmt.Fprintf(tw, "%s\t%s\t%.8s\t%s\n", result.Name, result.Channel, result.Commit, formatDate(result.Date))
+		}
+		return tw.Flush()
+	})
+}
+
+// selectChannels returns the channel with the name, every configured
+// channel when the name is empty.
+func selectChannels(name string) ([]tagchannel.Channel, error) {
+	channels, err := tagchannel.Load(core.Config())
+	if err != nil {
+		return nil, fmt.Errorf("loading tag channels: %w", err)
+	}
+	if len(name) == 0 {
+		return channels, nil
+	}
+
+	channel, err := tagchannel.Find(channels, name)
+	if err != nil {
+		return nil, err
+	}
+	return []tagchannel.Channel{*channel}, nil
+}
+
+// versionBound is a lower or upper limit of the listed versions, a bound
+// without a build number includes every build of the version.
+type versionBound struct {
+	version  *version.Version
+	anyBuild bool
+}
+
+func parseVersionBound(s string) (versionBound, error) {
+	if len(s) == 0 {
+		return versionBound{}, nil
+	}
+	if strings.Contains(s, "+") {
+		v, err := version.Parse(s)
+		return versionBound{version: v}, err
+	}
+
+	// the uList scheme requires a build number
+	v, err := version.Parse(s + "+0")
+	if err != nil {
+		return versionBound{}, err
+	}
+	return versionBound{version: v, anyBuild: true}, nil
+}
+
+// allows reports whether v is on the side of the bound given by sign, 1
+// for a lower bound and -1 for an upper one.
+func (b versionBound) allows(v version.Version, sign int) bool {
+	if b.version == nil {
+		return true
+	}
+	if b.anyBuild {
+		v.Build = b.version.Build
+	}
+	return v.Compare(*b.version)*sign >= 0
+}
+
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return "-"
+	}
+	return date.Format(time.DateOnly)
+}
@@ -0,0 +1,196 @@
+package commit_command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	cloudsql "ulist.app/ult/internal/cloud_sql"
+	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/output"
+	"ulist.app/ult/internal/release"
+	"ulist.app/ult/internal/tagchannel"
+)
+
+const (
+	flagKeep      = "keep"
+	flagOlderThan = "older-than"
+)
+
+// pruneResult is the output schema of a tag prune, Tags holds the decision
+// for every tag of the channel.
+type pruneResult struct {
+	Channel string      `json:"channel" yaml:"channel"`
+	Kept    int         `json:"kept" yaml:"kept"`
+	Pruned  int         `json:"pruned" yaml:"pruned"`
+	Tags    []prunedTag `json:"tags" yaml:"tags"`
+	DryRun  bool        `json:"dry_run" yaml:"dry_run"`
+}
+
+// prunedTag is the decision for a single tag.
+type prunedTag struct {
+	Name    string    `json:"name" yaml:"name"`
+	Version string    `json:"version" yaml:"version"`
+	Commit  string    `json:"commit" yaml:"commit"`
+	Date    time.Time `json:"date" yaml:"date"`
+	Pruned  bool      `json:"pruned" yaml:"pruned"`
+	Reason  string    `json:"reason" yaml:"reason"`
+}
+
+var pruneCmd = cli.Command{
+	Name: "prune",
+	Usage: "delete the old tags of a channel, keeping the newest versions and the tags of the releases recorded in Cloud SQL " +
+		"(uses the GitLab API unless --backend git; preview with --dry-run)",
+	Action: runPrune,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  flagChannel,
+			Usage: "channel whose tags are pruned, e.g. 'qa'",
+		},
+		&cli.IntFlag{
+			Name:  flagKeep,
+			Usage: "number of newest versions that are always kept",
+		},
+		&cli.StringFlag{
+			Name:  flagOlderThan,
+			Usage: "only prune tags older than this age, e.g. '90d', '12w' or '36h'",
+		},
+	},
+}
+
+func runPrune(ctx context.Context, cmd *cli.Command) error {
+	channelName := cmd.String(flagChannel)
+	if len(channelName) == 0 {
+		return fmt.Errorf("the channel must be provided '--%s=qa'", flagChannel)
+	}
+	channels, err := selectChannels(channelName)
+	if err != nil {
+		return err
+	}
+
+	policy := tagchannel.Policy{Keep: int(cmd.Int(flagKeep))}
+	if olderThan := cmd.String(flagOlderThan); len(olderThan) > 0 {
+		if policy.OlderThan, err = tagchannel.ParseAge(olderThan); err != nil {
+			return err
+		}
+	}
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("%w ('--%s' or '--%s')", err, flagKeep, flagOlderThan)
+	}
+
+	repo, err := core.NewRepository(cmd, core.RepositoryBackend(cmd, git.BackendGitlab))
+	if err != nil {
+		return err
+	}
+
+	// the released tags are looked up before anything is deleted, without
+	// them no tag is safe to delete
+	released, err := fetchReleased(channels[0].HasBuild())
+	if err != nil {
+		return err
+	}
+
+	tags, err := tagchannel.List(repo, channels)
+	if err != nil {
+		return err
+	}
+	logger.Info("pruning tags", "channel", channels[0].Name, "tags", len(tags), "keep", policy.Keep, "older_than", policy.OlderThan, "backend", repo.Backend())
+
+	result := pruneResult{Channel: channels[0].Name, DryRun: dryrun.Enabled()}
+	failed := 0
+	for _, decision := range policy.Apply(tags, released, time.Now()) {
+		tag := prunedTag{
+			Name:    decision.Tag.Name,
+			Version: decision.Tag.Version.String(),
+			Commit:  decision.Tag.Commit,
+			Date:    decision.Tag.Date,
+			Pruned:  decision.Prune,
+			Reason:  decision.Reason,
+		}
+		if decision.Prune {
+			if err := repo.DeleteTag(tag.Name); err != nil {
+				logger.Error("failed to delete tag", "tag", tag.Name, "error", err)
+				tag.Pruned, tag.Reason = false, err.Error()
+				failed++
+			} else {
+				logger.Info("deleted tag", "tag", tag.Name, "commit", tag.Commit)
+			}
+		}
+
+		if tag.Pruned {
+			result.Pruned++
+		} else {
+			result.Kept++
+		}
+		result.Tags = append(result.Tags, tag)
+	}
+
+	err = output.Print(result, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TAG\tDATE\tACTION\tREASON")
+		for _, tag := range result.Tags {
+			action := "keep"
+			if tag.Pruned {
+				action = "delete"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", tag.Name, formatDate(tag.Date), action, tag.Reason)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		summary := "deleted %d tags of channel %s, kept %d\n"
+		if result.DryRun {
+			summary = "would delete %d tags of channel %s, keep %d\n"
+		}
+		_, err := fmt.Fprintf(w, "\n"+summary, result.Pruned, result.Channel, result.Kept)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d tags of channel %s could not be deleted", failed, result.Channel)
+	}
+	return nil
+}
+
+// fetchReleased returns whether a tag points to the commit or has the
+// version of a release recorded in Cloud SQL, the build number is only
+// compared when the tags hold it.
+func fetchReleased(hasBuild bool) (func(tagchannel.Tag) bool, error) {
+	db, err := cloudsql.ConnectWithConnector()
+	if err != nil {
+		return nil, fmt.Errorf("connecting to cloud sql to look up the released tags: %w", err)
+	}
+	defer db.Close()
+
+	releases, err := release.FetchReleases(db)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("fetched releases", "count", len(releases))
+
+	return func(tag tagchannel.Tag) bool {
+		for _, r := range releases {
+			released := r.Version
+			if !hasBuild {
+				released.Build = tag.Version.Build
+			}
+			if tag.Version.Equal(released) {
+				return true
+			}
+			if len(r.Commit) > 0 && len(tag.Commit) > 0 && (strings.HasPrefix(tag.Commit, r.Commit) || strings.HasPrefix(r.Commit, tag.Commit)) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
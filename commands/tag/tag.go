@@ -2,26 +2,50 @@ package commit_command
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log/slog"
-	"os"
+	"io"
 	"strings"
 
 	"github.com/urfave/cli/v3"
-	gitlab "gitlab.com/gitlab-org/api/client-go"
 	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/output"
+	"ulist.app/ult/internal/pubspec"
+	"ulist.app/ult/internal/tagchannel"
+	"ulist.app/ult/internal/tagmessage"
 	"ulist.app/ult/internal/version"
 )
 
 const (
 	flagRef        = "ref"
 	flagUseVersion = "use-pubspec-version"
+	flagTagMessage = "tag-message"
+	flagMove       = "move"
+	flagChannel    = "channel"
 )
 
 var (
-	logger = slog.Default().WithGroup("tag_command")
+	logger = logging.New("tag_command")
 )
 
+// tagResult is the output schema of a created tag.
+type tagResult struct {
+	Name   string `json:"name" yaml:"name"`
+	Ref    string `json:"ref" yaml:"ref"`
+	Commit string `json:"commit" yaml:"commit"`
+	// Channel is the tag channel the name was generated with
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+	// Previous is the commit a moved or existing tag pointed to
+	Previous string `json:"previous,omitempty" yaml:"previous,omitempty"`
+	Moved    bool   `json:"moved" yaml:"moved"`
+	Message  string `json:"message,omitempty" yaml:"message,omitempty"`
+	Signed   bool   `json:"signed" yaml:"signed"`
+	DryRun   bool   `json:"dry_run" yaml:"dry_run"`
+}
+
 var Cmd = cli.Command{
 	Name:   "tag",
 	Usage:  "create a new git tag pointing to a commit, branch, or another tag",
@@ -30,82 +54,137 @@ var Cmd = cli.Command{
 		&cli.StringFlag{
 			Name:    flagRef,
 			Aliases: []string{"r"},
-			Usage:   "commit SHA, branch name, or existing tag to point the new tag at",
+			Usage:   "commit SHA, branch name, or existing tag to point the new tag at (defaults to HEAD with --backend git)",
 		},
 		&cli.BoolFlag{
 			Name:  flagUseVersion,
 			Usage: "use the version from pubspec.yaml as the tag name",
 			Value: false,
 		},
+		&cli.StringFlag{
+			Name:  flagChannel,
+			Usage: "name the tag after the pubspec.yaml version with the template of the tag channel, e.g. 'qa' (see tag-channels in .ult.yaml)",
+		},
+		&cli.BoolFlag{
+			Name:  flagMove,
+			Usage: "move the tag when it already points to another commit, the old and new commit are logged for auditing",
+		},
+		&cli.StringFlag{
+			Name:  flagTagMessage,
+			Usage: "create an annotated tag with this message, supports {tag}, {changelog} (commits since the previous tag, git backend only), {pipeline_url} and the {version} placeholders (signed tags default to '" + tagmessage.DefaultTemplate + "')",
+		},
+	},
+	Commands: []*cli.Command{
+		&verifyCmd,
+		&listCmd,
+		&pruneCmd,
 	},
 }
 
 func run(ctx context.Context, cmd *cli.Command) error {
-	token, err := core.GetToken(cmd)
+	// the tag command always used the GitLab API before the backend setting existed
+	repo, err := core.NewRepository(cmd, core.RepositoryBackend(cmd, git.BackendGitlab))
 	if err != nil {
 		return err
 	}
-	projectId, projectIdErr := core.GetProjectID(cmd)
 	ref := cmd.String(flagRef)
 	tagName := cmd.Args().First()
 	useVersionAsTagName := cmd.Bool(flagUseVersion)
-	if len(tagName) == 0 && !useVersionAsTagName {
-		return fmt.Errorf("a tag name must be provided as positional argument (usage: ult tag v1.0.0) or pass '--%s'", flagUseVersion)
+	channelName := cmd.String(flagChannel)
+	if len(tagName) == 0 && !useVersionAsTagName && len(channelName) == 0 {
+		return fmt.Errorf("a tag name must be provided as positional argument (usage: ult tag v1.0.0) or pass '--%s' or '--%s'", flagUseVersion, flagChannel)
+	}
+	if len(tagName) > 0 && len(channelName) > 0 {
+		return fmt.Errorf("the tag name is generated by '--%s', it can not be provided as positional argument", flagChannel)
+	}
+
+	var channel *tagchannel.Channel
+	if len(channelName) > 0 {
+		channels, err := tagchannel.Load(core.Config())
+		if err != nil {
+			return fmt.Errorf("loading tag channels: %w", err)
+		}
+		if channel, err = tagchannel.Find(channels, channelName); err != nil {
+			return err
+		}
 	}
 	logger.Info("creating tag",
 		"name", tagName,
 		"ref", ref,
 		"use_pubspec_version", useVersionAsTagName,
-		"project", projectId,
+		"channel", channelName,
+		"backend", repo.Backend(),
 	)
 
-	if projectIdErr != nil {
-		return err
-	}
-
-	if useVersionAsTagName {
-		version, err := fetchVersionFromPubspecFile()
+	template := core.GetString(cmd, flagTagMessage)
+	var ver *version.Version
+	if useVersionAsTagName || channel != nil || hasVersionPlaceholder(template) {
+		ver, err = fetchVersionFromPubspecFile()
 		if err != nil {
 			return err
 		}
-
-		tagName = version.String()
 	}
-
-	appRepo, err := gitlab.NewClient(token)
-	if err != nil {
-		return err
+	switch {
+	case channel != nil:
+		tagName = channel.TagName(*ver)
+	case useVersionAsTagName:
+		tagName = ver.String()
 	}
 
-	opt := &gitlab.CreateTagOptions{
-		TagName: gitlab.Ptr(tagName),
-		Ref:     gitlab.Ptr(ref),
+	message := tagmessage.Build(template, tagmessage.Data{
+		Tag:         tagName,
+		Version:     ver,
+		PipelineURL: core.GetString(cmd, core.PipelineFlag),
+	}, ref, repo.Backend() == git.BackendGit)
+
+	result := tagResult{Name: tagName, Ref: ref, Channel: channelName, Message: message, Signed: git.SigningEnabled(), DryRun: dryrun.Enabled()}
+	change, err := repo.CreateTag(tagName, ref, message, cmd.Bool(flagMove))
+	if errors.Is(err, git.ErrTagExists) {
+		return fmt.Errorf("%w, pass '--%s' to move it", err, flagMove)
 	}
-	tag, _, err := appRepo.Tags.CreateTag(projectId, opt)
 	if err != nil {
 		return err
 	}
+	result.Commit, result.Previous, result.Moved = change.Commit, change.Previous, change.Moved()
 
-	fmt.Printf("Successfully tagged ref: %s\n", tag.Commit.ID)
-
-	return nil
+	return output.Print(result, func(w io.Writer) error {
+		if result.DryRun {
+			return nil
+		}
+		var err error
+		switch {
+		case change.Unchanged():
+			_, err = fmt.Fprintf(w, "Tag %s already points to ref: %s\n", result.Name, result.Commit)
+		case change.Moved():
+			_, err = fmt.Fprintf(w, "Moved tag %s from %s to ref: %s\n", result.Name, result.Previous, result.Commit)
+		default:
+			_, err = fmt.Fprintf(w, "Successfully tagged ref: %s\n", result.Commit)
+		}
+		return err
+	})
 }
 
-func fetchVersionFromPubspecFile() (*version.Version, error) {
-	contents, err := os.ReadFile("pubspec.yaml")
-	if err != nil {
-		logger.Error("Failed to read pubspec.yaml", "error", err)
-		return nil, fmt.Errorf("reading pubspec.yaml: %w", err)
+// hasVersionPlaceholder reports whether the tag message needs the version.
+func hasVersionPlaceholder(template string) bool {
+	for _, placeholder := range []string{
+		version.PlaceholderVersion,
+		version.PlaceholderVersionNoBuild,
+		version.PlaceholderBuild,
+		version.PlaceholderPreRelease,
+	} {
+		if strings.Contains(template, placeholder) {
+			return true
+		}
 	}
+	return false
+}
 
-	lines := strings.Split(string(contents), "\n")
-	logger.Debug("Read pubspec.yaml", "lineCount", len(lines))
-
-	version, _, err := version.FetchFromLines(lines)
+func fetchVersionFromPubspecFile() (*version.Version, error) {
+	version, err := pubspec.ReadVersion(pubspec.DefaultPath)
 	if err != nil {
-		logger.Error("Failed to parse version", "error", err)
-		return version, fmt.Errorf("parsing version: %w", err)
+		logger.Error("Failed to read version from pubspec.yaml", "error", err)
+		return nil, fmt.Errorf("reading version from pubspec.yaml: %w", err)
 	}
 
-	return version, err
+	return version, nil
 }
@@ -0,0 +1,87 @@
+package commit_command
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/urfave/cli/v3"
+	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/output"
+)
+
+const (
+	flagPattern = "pattern"
+)
+
+var verifyCmd = cli.Command{
+	Name:      "verify",
+	Usage:     "check that release tags are annotated and signed with a trusted key (git backend only)",
+	ArgsUsage: "[tag...]",
+	Action:    runVerify,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  flagPattern,
+			Usage: "verify every local tag matching the glob pattern, e.g. 'v*'",
+		},
+	},
+}
+
+func runVerify(ctx context.Context, cmd *cli.Command) error {
+	// tag objects and signatures are only available in the local clone
+	if backend := core.RepositoryBackend(cmd, git.BackendGit); backend != git.BackendGit {
+		return fmt.Errorf("tag verify requires '--backend %s', the GitLab API does not expose tag signatures", git.BackendGit)
+	}
+
+	tags := cmd.Args().Slice()
+	if pattern := cmd.String(flagPattern); len(pattern) > 0 {
+		matched, err := git.ListTags(pattern)
+		if err != nil {
+			return err
+		}
+		tags = append(tags, matched...)
+	}
+	if len(tags) == 0 {
+		return fmt.Errorf("the tags must be provided as positional arguments (usage: ult tag verify v1.0.0) or with '--%s'", flagPattern)
+	}
+
+	results := make([]git.TagVerification, 0, len(tags))
+	failed := 0
+	for _, tag := range tags {
+		result, err := git.VerifyTag(tag)
+		if err != nil {
+			return err
+		}
+		if !result.Verified() {
+			failed++
+			logger.Warn("Tag is not verified", "tag", tag, "annotated", result.Annotated, "signature", result.Signature)
+		}
+		results = append(results, *result)
+	}
+
+	err := output.Print(results, func(w io.Writer) error {
+		for _, result := range results {
+			kind := "lightweight"
+			if result.Annotated {
+				kind = "annotated"
+			}
+			line := fmt.Sprintf("%s: %s, signature %s", result.Tag, kind, result.Signature)
+			if len(result.Signer) > 0 {
+				line += " by " + result.Signer
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tags are not signed with a good, trusted signature", failed, len(results))
+	}
+	return nil
+}
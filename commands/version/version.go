@@ -0,0 +1,154 @@
+// Package version_command provides commands to compare and sort versions
+// following the project version scheme, so scripts can gate on them.
+package version_command
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/output"
+	"ulist.app/ult/internal/version"
+)
+
+const (
+	flagExpect  = "expect"
+	flagReverse = "reverse"
+)
+
+var (
+	logger = logging.New("version_command")
+)
+
+// compareResult is the output schema of a version comparison.
+type compareResult struct {
+	A      string `json:"a" yaml:"a"`
+	B      string `json:"b" yaml:"b"`
+	Result int    `json:"result" yaml:"result"`
+}
+
+// sortedVersion is a single entry of the sort command output.
+type sortedVersion struct {
+	Input   string `json:"input" yaml:"input"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// CompareCmd compares two versions.
+var CompareCmd = cli.Command{
+	Name:      "compare",
+	Usage:     "compare two versions, prints -1 if A is lower than B, 0 if they are equal and 1 if A is greater",
+	ArgsUsage: "A B",
+	Action:    runCompare,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  flagExpect,
+			Usage: "fail unless the comparison of A to B holds: lt, le, eq, ge or gt (e.g. --expect=gt to require A newer than B)",
+		},
+	},
+}
+
+// SortCmd sorts the versions read from stdin.
+var SortCmd = cli.Command{
+	Name:   "sort",
+	Usage:  "sort the versions read from stdin, one per line (lines may contain other text like tag names, e.g. QA-v2025.200.01+04)",
+	Action: runSort,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    flagReverse,
+			Aliases: []string{"r"},
+			Usage:   "sort from the greatest to the lowest version",
+		},
+	},
+}
+
+func runCompare(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 2 {
+		return errors.New("expected two versions.\nUsage: 'ult version compare 2025.200.01+04 2025.100.03+12'")
+	}
+
+	var relation version.Relation
+	if cmd.IsSet(flagExpect) {
+		var err error
+		relation, err = version.ParseRelation(cmd.String(flagExpect))
+		if err != nil {
+			return err
+		}
+	}
+
+	a, err := version.Parse(cmd.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("parsing version A: %w", err)
+	}
+	b, err := version.Parse(cmd.Args().Get(1))
+	if err != nil {
+		return fmt.Errorf("parsing version B: %w", err)
+	}
+
+	result := compareResult{A: a.String(), B: b.String(), Result: version.Compare(*a, *b)}
+	logger.Debug("compared versions", "a", result.A, "b", result.B, "result", result.Result)
+
+	if err := output.Print(result, func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, result.Result)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if len(relation) > 0 && !relation.Holds(result.Result) {
+		return fmt.Errorf("expected %s %s %s", result.A, relation, result.B)
+	}
+	return nil
+}
+
+func runSort(ctx context.Context, cmd *cli.Command) error {
+	type entry struct {
+		input   string
+		version *version.Version
+	}
+
+	entries := []entry{}
+	scanner := bufio.NewScanner(os.Stdin)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		v, err := version.Parse(line)
+		if err != nil {
+			return fmt.Errorf("parsing line %d: %w", lineNumber, err)
+		}
+		entries = append(entries, entry{input: line, version: v})
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading stdin: %w", err)
+	}
+
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		if cmd.Bool(flagReverse) {
+			return version.Compare(*b.version, *a.version)
+		}
+		return version.Compare(*a.version, *b.version)
+	})
+
+	result := make([]sortedVersion, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, sortedVersion{Input: e.input, Version: e.version.String()})
+	}
+
+	return output.Print(result, func(w io.Writer) error {
+		for _, v := range result {
+			if _, err := fmt.Fprintln(w, v.Input); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -9,9 +9,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/charmbracelet/log"
 	"golang.org/x/oauth2/jwt"
 	"google.golang.org/api/firebaseappdistribution/v1"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/logging"
 )
 
 type ReleaseInfo struct {
@@ -19,10 +20,7 @@ type ReleaseInfo struct {
 }
 
 var (
-	logger = log.NewWithOptions(os.Stdout, log.Options{
-		Level:           log.DebugLevel,
-		ReportTimestamp: true,
-	})
+	logger = logging.New("app_distribution")
 )
 
 func CreateReleaseWithFile(ctx context.Context, binary *os.File, appName string, jwt *jwt.Config) (*ReleaseInfo, error) {
@@ -55,6 +53,10 @@ func CreateReleaseWithFile(ctx context.Context, binary *os.File, appName string,
 		return nil, fmt.Errorf("failed to get file info: %w", err)
 	}
 	logger.Debug("file size", "bytes", fileInfo.Size())
+	if dryrun.Enabled() {
+		dryrun.Printf("upload %s (%d bytes, %s) to %s", binary.Name(), fileInfo.Size(), contentType, uploadURL)
+		return &ReleaseInfo{}, nil
+	}
 	progressReader := &ProgressPrinter{Reader: binary, Total: fileInfo.Size()}
 	req, err := http.NewRequestWithContext(uploadCtx, "POST", uploadURL, progressReader)
 	if err != nil {
@@ -126,6 +128,11 @@ func PollOperation(name string, service *firebaseappdistribution.Service) (*Rele
 
 func AddReleaseNotesToRelease(releaseName string, notes string, service *firebaseappdistribution.Service) error {
 	logger.Info("updating release with release notes")
+	if dryrun.Enabled() {
+		dryrun.Printf("set release notes of %s to: %q", releaseName, notes)
+		return nil
+	}
+
 	releaseNotes := firebaseappdistribution.GoogleFirebaseAppdistroV1ReleaseNotes{Text: notes}
 	req := firebaseappdistribution.GoogleFirebaseAppdistroV1Release{ReleaseNotes: &releaseNotes}
 	_, err := service.Projects.Apps.Releases.Patch(releaseName, &req).Do()
@@ -139,6 +146,11 @@ func AddReleaseNotesToRelease(releaseName string, notes string, service *firebas
 
 func DistributeRelease(releaseName string, groups []string, service *firebaseappdistribution.Service) error {
 	logger.Info("distributing the app", "groups", groups)
+	if dryrun.Enabled() {
+		dryrun.Printf("distribute %s to groups %v", releaseName, groups)
+		return nil
+	}
+
 	distReq := firebaseappdistribution.GoogleFirebaseAppdistroV1DistributeReleaseRequest{
 		GroupAliases: groups,
 	}
@@ -3,6 +3,7 @@ package appdistribution
 import (
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"time"
 )
@@ -30,7 +31,7 @@ func (pr *ProgressPrinter) Read(p []byte) (int, error) {
 	now := time.Now()
 	if now.Sub(pr.LatestUpdate).Milliseconds() > 300 {
 		percent := float64(pr.Current) / float64(pr.Total) * 100
-		fmt.Printf("Upload progress: %s\r", fmt.Sprintf("%.1f%%", percent))
+		fmt.Fprintf(os.Stderr, "Upload progress: %s\r", fmt.Sprintf("%.1f%%", percent))
 		pr.LatestUpdate = now
 	}
 
@@ -56,12 +57,12 @@ func contentTypeByExtension(extension string) (string, error) {
 
 // return a new character to print a loading spinner
 func printLoadingSpinner() {
-	fmt.Printf(cleanLine)
+	fmt.Fprint(os.Stderr, cleanLine)
 	char := spinner[int(time.Now().UnixNano()/100000000)%len(spinner)]
-	fmt.Printf(char)
+	fmt.Fprint(os.Stderr, char)
 }
 
 // clean the line that the loading spinner was using
 func cleanLoadingSpinner() {
-	fmt.Printf(cleanLine)
+	fmt.Fprint(os.Stderr, cleanLine)
 }
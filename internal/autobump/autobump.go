@@ -0,0 +1,239 @@
+// Package autobump infers the bump type of a release from the branch name,
+// the conventional commits since the last tag and the calendar.
+package autobump
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"ulist.app/ult/internal/config"
+	"ulist.app/ult/internal/version"
+)
+
+// ConfigKey is the .ult.yaml key holding the bump rules.
+const ConfigKey = "bump-rules"
+
+// BreakingChange is the commit rule key matching breaking changes, either
+// "type!:" subjects or a "BREAKING CHANGE:" footer.
+const BreakingChange = "breaking"
+
+// BranchRule selects the bump type of branches matching the glob pattern,
+// e.g. "hotfix/*".
+type BranchRule struct {
+	Pattern string `yaml:"pattern"`
+	Bump    string `yaml:"bump"`
+}
+
+// Rules configure how the bump type is inferred. Commits maps conventional
+// commit types (feat, fix, ...) and BreakingChange to bump types. Calendar
+// enables the year bump when the current year is past the version year.
+type Rules struct {
+	Default  string            `yaml:"default"`
+	Calendar *bool             `yaml:"calendar"`
+	Branches []BranchRule      `yaml:"branches"`
+	Commits  map[string]string `yaml:"commits"`
+}
+
+// Input is what the bump type is inferred from.
+type Input struct {
+	Version version.Version
+	Branch  string
+	// Commits are the full messages of the commits since the last tag.
+	Commits []string
+	Now     time.Time
+}
+
+// Decision is the inferred bump type together with the reasons that led
+// to it, in the order they were evaluated.
+type Decision struct {
+	BumpType version.BumpType
+	Reasons  []string
+}
+
+var conventionalCommitRegex = regexp.MustCompile(`^(\w+)(?:\([^)]*\))?(!)?: `)
+var breakingFooterRegex = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+
+// DefaultRules returns the rules used when none are configured, matching
+// the meaning of the components of the given scheme.
+func DefaultRules(scheme version.Scheme) Rules {
+	if scheme.Name() == version.UList.Name() {
+		return Rules{
+			Default: "build",
+			Branches: []BranchRule{
+				{Pattern: "hotfix/*", Bump: "minor"},
+				{Pattern: "release/*", Bump: "major"},
+			},
+			Commits: map[string]string{
+				"fix":          "minor",
+				"perf":         "minor",
+				"feat":         "major",
+				BreakingChange: "milestone",
+			},
+		}
+	}
+
+	return Rules{
+		Default: "build",
+		Branches: []BranchRule{
+			{Pattern: "hotfix/*", Bump: "patch"},
+			{Pattern: "release/*", Bump: "minor"},
+		},
+		Commits: map[string]string{
+			"fix":          "patch",
+			"perf":         "patch",
+			"feat":         "minor",
+			BreakingChange: "major",
+		},
+	}
+}
+
+// LoadRules reads the rules from the configuration, settings that are not
+// configured keep the default rules of the scheme.
+func LoadRules(cfg *config.Config, scheme version.Scheme) (Rules, error) {
+	configured := Rules{}
+	if _, err := cfg.Decode(ConfigKey, &configured); err != nil {
+		return Rules{}, err
+	}
+
+	rules := DefaultRules(scheme)
+	if len(configured.Default) > 0 {
+		rules.Default = configured.Default
+	}
+	if configured.Calendar != nil {
+		rules.Calendar = configured.Calendar
+	}
+	if configured.Branches != nil {
+		rules.Branches = configured.Branches
+	}
+	if configured.Commits != nil {
+		rules.Commits = configured.Commits
+	}
+
+	return rules, rules.validate()
+}
+
+func (r Rules) validate() error {
+	bumps := []string{r.Default}
+	for _, rule := range r.Branches {
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid branch pattern in %s: %s", ConfigKey, rule.Pattern)
+		}
+		bumps = append(bumps, rule.Bump)
+	}
+	for _, bump := range r.Commits {
+		bumps = append(bumps, bump)
+	}
+
+	for _, bump := range bumps {
+		if _, err := version.ParseBumpType(bump); err != nil {
+			return fmt.Errorf("invalid rule in %s: %w", ConfigKey, err)
+		}
+	}
+	return nil
+}
+
+// Infer returns the greatest bump type of every matching rule, falling back
+// to the default bump type when no rule matches.
+func Infer(rules Rules, input Input) (Decision, error) {
+	defaultBump, err := version.ParseBumpType(rules.Default)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	decision := Decision{BumpType: defaultBump}
+	matched := false
+	consider := func(bump string, reason string) {
+		bumpType, err := version.ParseBumpType(bump)
+		if err != nil {
+			return
+		}
+		decision.Reasons = append(decision.Reasons, fmt.Sprintf("%s -> %s", reason, bumpType))
+		if !matched || rank(bumpType) > rank(decision.BumpType) {
+			decision.BumpType = bumpType
+		}
+		matched = true
+	}
+
+	if rules.Calendar == nil || *rules.Calendar {
+		if year, ok := calendarYear(input.Version); ok && input.Now.Year() > year {
+			consider("year", fmt.Sprintf("current year %d is past the version year %d", input.Now.Year(), year))
+		}
+	}
+
+	for _, rule := range rules.Branches {
+		if ok, _ := path.Match(rule.Pattern, input.Branch); ok {
+			consider(rule.Bump, fmt.Sprintf("branch %s matches %s", input.Branch, rule.Pattern))
+			break
+		}
+	}
+
+	for _, message := range input.Commits {
+		commitType, breaking := parseConventionalCommit(message)
+		subject, _, _ := strings.Cut(message, "\n")
+		if breaking {
+			if bump, ok := rules.Commits[BreakingChange]; ok {
+				consider(bump, fmt.Sprintf("breaking change in commit %q", subject))
+				continue
+			}
+		}
+		if bump, ok := rules.Commits[commitType]; ok {
+			consider(bump, fmt.Sprintf("%s commit %q", commitType, subject))
+		}
+	}
+
+	if !matched {
+		decision.Reasons = append(decision.Reasons, fmt.Sprintf("no rule matched, using the default: %s", defaultBump))
+	}
+	return decision, nil
+}
+
+// parseConventionalCommit returns the type of a conventional commit message
+// like "feat(login)!: add sso" and whether it is a breaking change.
+func parseConventionalCommit(message string) (string, bool) {
+	matches := conventionalCommitRegex.FindStringSubmatch(message)
+	if matches == nil {
+		return "", false
+	}
+
+	breaking := len(matches[2]) > 0 || breakingFooterRegex.MatchString(message)
+	return strings.ToLower(matches[1]), breaking
+}
+
+// calendarYear returns the four digit year of versions whose scheme is
+// based on the calendar.
+func calendarYear(v version.Version) (int, bool) {
+	scheme := v.Scheme
+	if scheme == nil {
+		scheme = version.Default()
+	}
+
+	switch scheme.Name() {
+	case version.UList.Name():
+		return v.Year, true
+	case version.CalVer.Name():
+		return 2000 + v.Year, true
+	}
+	return 0, false
+}
+
+// rank orders the bump types from the smallest to the greatest change.
+func rank(bumpType version.BumpType) int {
+	switch bumpType {
+	case version.BumpTypeYear:
+		return 6
+	case version.BumpTypeMilestone:
+		return 5
+	case version.BumpTypeMajor:
+		return 4
+	case version.BumpTypeMinor:
+		return 3
+	case version.BumpTypePatch:
+		return 2
+	case version.BumpTypeBuild:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,115 @@
+package autobump
+
+import (
+	"testing"
+	"time"
+
+	"ulist.app/ult/internal/version"
+)
+
+func TestInfer(t *testing.T) {
+	now := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+	current := version.Version{Year: 2025, Major: 200, Minor: 1, Build: 4, Scheme: version.UList}
+	disabled := false
+
+	tt := []struct {
+		name  string
+		rules Rules
+		input Input
+		want  version.BumpType
+	}{
+		{
+			name:  "default",
+			rules: DefaultRules(version.UList),
+			input: Input{Version: current, Branch: "main", Commits: []string{"update readme"}, Now: now},
+			want:  version.BumpTypeBuild,
+		},
+		{
+			name:  "hotfix branch",
+			rules: DefaultRules(version.UList),
+			input: Input{Version: current, Branch: "hotfix/crash-on-login", Now: now},
+			want:  version.BumpTypeMinor,
+		},
+		{
+			name:  "greatest commit wins",
+			rules: DefaultRules(version.UList),
+			input: Input{Version: current, Branch: "hotfix/app-12", Commits: []string{"fix: crash", "feat(login): sso", "chore: deps"}, Now: now},
+			want:  version.BumpTypeMajor,
+		},
+		{
+			name:  "breaking subject",
+			rules: DefaultRules(version.UList),
+			input: Input{Version: current, Commits: []string{"feat!: new navigation"}, Now: now},
+			want:  version.BumpTypeMilestone,
+		},
+		{
+			name:  "breaking footer",
+			rules: DefaultRules(version.UList),
+			input: Input{Version: current, Commits: []string{"refactor: api\n\nBREAKING CHANGE: drops v1"}, Now: now},
+			want:  version.BumpTypeMilestone,
+		},
+		{
+			name:  "new year",
+			rules: DefaultRules(version.UList),
+			input: Input{Version: current, Commits: []string{"feat!: new navigation"}, Now: now.AddDate(1, 0, 0)},
+			want:  version.BumpTypeYear,
+		},
+		{
+			name:  "calendar disabled",
+			rules: Rules{Default: "build", Calendar: &disabled},
+			input: Input{Version: current, Now: now.AddDate(1, 0, 0)},
+			want:  version.BumpTypeBuild,
+		},
+		{
+			name:  "semver ignores calendar",
+			rules: DefaultRules(version.SemVer),
+			input: Input{Version: version.Version{Year: 1, Major: 2, Minor: 3, Scheme: version.SemVer}, Commits: []string{"fix: typo"}, Now: now},
+			want:  version.BumpTypePatch,
+		},
+		{
+			name: "custom branch pattern",
+			rules: Rules{
+				Default:  "build",
+				Branches: []BranchRule{{Pattern: "feature/app-*", Bump: "major"}},
+			},
+			input: Input{Version: current, Branch: "feature/app-123", Now: now},
+			want:  version.BumpTypeMajor,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Infer(tc.rules, tc.input)
+			if err != nil {
+				t.Fatalf("Infer() error = %v", err)
+			}
+			if got.BumpType != tc.want {
+				t.Errorf("Infer() = %s, want %s (reasons: %v)", got.BumpType, tc.want, got.Reasons)
+			}
+			if len(got.Reasons) == 0 {
+				t.Error("Infer() returned no reasons")
+			}
+		})
+	}
+}
+
+func TestParseConventionalCommit(t *testing.T) {
+	tt := []struct {
+		message      string
+		wantType     string
+		wantBreaking bool
+	}{
+		{message: "feat: add login", wantType: "feat"},
+		{message: "fix(api): timeout", wantType: "fix"},
+		{message: "Feat(ui)!: redesign", wantType: "feat", wantBreaking: true},
+		{message: "chore: deps\n\nBREAKING-CHANGE: node 20", wantType: "chore", wantBreaking: true},
+		{message: "bump version [skip ci]", wantType: ""},
+	}
+
+	for _, tc := range tt {
+		gotType, gotBreaking := parseConventionalCommit(tc.message)
+		if gotType != tc.wantType || gotBreaking != tc.wantBreaking {
+			t.Errorf("parseConventionalCommit(%q) = %q, %t, want %q, %t", tc.message, gotType, gotBreaking, tc.wantType, tc.wantBreaking)
+		}
+	}
+}
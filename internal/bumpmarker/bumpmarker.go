@@ -0,0 +1,112 @@
+// Package bumpmarker recognizes the commits created by a version bump, so a
+// bump is done only once per merge request or branch (release bump --once).
+package bumpmarker
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ulist.app/ult/internal/config"
+	"ulist.app/ult/internal/git"
+)
+
+// ConfigKey is the .ult.yaml key holding the marker.
+const ConfigKey = "bump-marker"
+
+// DefaultText is the marker of the default bump commit message.
+const DefaultText = "bump version [skip ci]"
+
+// Marker matches a bump commit by any of its criteria: Text contained in the
+// subject, Regex matching the full message, or a Trailer of the message like
+// "Ult-Bump" (any value) or "Ult-Bump: build" (that value, case-insensitive).
+type Marker struct {
+	Text    string `yaml:"text"`
+	Regex   string `yaml:"regex"`
+	Trailer string `yaml:"trailer"`
+
+	regex *regexp.Regexp
+}
+
+// Load reads the marker from the configuration, defaulting to DefaultText.
+func Load(cfg *config.Config) (Marker, error) {
+	m := Marker{}
+	if _, err := cfg.Decode(ConfigKey, &m); err != nil {
+		return Marker{}, err
+	}
+	return New(m.Text, m.Regex, m.Trailer)
+}
+
+// New returns a marker with the given criteria, a marker without criteria
+// matches DefaultText.
+func New(text, regex, trailer string) (Marker, error) {
+	m := Marker{Text: text, Regex: regex, Trailer: strings.TrimSpace(trailer)}
+	if len(m.Text) == 0 && len(m.Regex) == 0 && len(m.Trailer) == 0 {
+		m.Text = DefaultText
+	}
+
+	if len(m.Regex) > 0 {
+		var err error
+		m.regex, err = regexp.Compile(m.Regex)
+		if err != nil {
+			return Marker{}, fmt.Errorf("invalid %s regex: %w", ConfigKey, err)
+		}
+	}
+	if len(m.Trailer) > 0 {
+		if key, _ := m.trailer(); len(key) == 0 || strings.ContainsAny(key, " \t") {
+			return Marker{}, errors.New("invalid " + ConfigKey + " trailer, expected 'Key' or 'Key: value'")
+		}
+	}
+	return m, nil
+}
+
+// Match reports whether the commit message is a bump commit.
+func (m Marker) Match(message string) bool {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	if len(m.Text) > 0 && strings.Contains(subject, m.Text) {
+		return true
+	}
+	if m.regex != nil && m.regex.MatchString(message) {
+		return true
+	}
+
+	if len(m.Trailer) > 0 {
+		key, value := m.trailer()
+		for _, trailer := range git.ParseTrailers(message) {
+			if strings.EqualFold(trailer.Key, key) && (len(value) == 0 || strings.EqualFold(trailer.Value, value)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FindIn returns the first of the commit messages that is a bump commit.
+func (m Marker) FindIn(messages []string) (string, bool) {
+	for _, message := range messages {
+		if m.Match(message) {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+func (m Marker) String() string {
+	criteria := []string{}
+	if len(m.Text) > 0 {
+		criteria = append(criteria, fmt.Sprintf("text %q", m.Text))
+	}
+	if len(m.Regex) > 0 {
+		criteria = append(criteria, fmt.Sprintf("regex %q", m.Regex))
+	}
+	if len(m.Trailer) > 0 {
+		criteria = append(criteria, fmt.Sprintf("trailer %q", m.Trailer))
+	}
+	return strings.Join(criteria, " or ")
+}
+
+func (m Marker) trailer() (string, string) {
+	key, value, _ := strings.Cut(m.Trailer, ":")
+	return strings.TrimSpace(key), strings.TrimSpace(value)
+}
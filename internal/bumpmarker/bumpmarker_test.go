@@ -0,0 +1,46 @@
+package bumpmarker
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tt := []struct {
+		name    string
+		text    string
+		regex   string
+		trailer string
+		message string
+		want    bool
+	}{
+		{name: "default", message: "bump version [skip ci]", want: true},
+		{name: "default in body only", message: "fix: crash\n\nnot a bump version [skip ci]", want: false},
+		{name: "text", text: "chore(release):", message: "chore(release): 2025.200.01+04", want: true},
+		{name: "regex", regex: `(?m)^release \d+`, message: "merge\n\nrelease 42", want: true},
+		{name: "regex no match", regex: `^release \d+$`, message: "feat: release notes", want: false},
+		{name: "trailer key", trailer: "Ult-Bump", message: "chore: version\n\nUlt-Bump: minor", want: true},
+		{name: "trailer value", trailer: "Ult-Bump: build", message: "chore: version\n\nult-bump: Build", want: true},
+		{name: "trailer other value", trailer: "Ult-Bump: build", message: "chore: version\n\nUlt-Bump: minor", want: false},
+		{name: "trailer in subject", trailer: "Ult-Bump", message: "Ult-Bump: build", want: false},
+		{name: "any criterion", text: "bump", trailer: "Ult-Bump", message: "chore: version\n\nUlt-Bump: build\nSigned-off-by: CI <ci@example.com>", want: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := New(tc.text, tc.regex, tc.trailer)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got := m.Match(tc.message); got != tc.want {
+				t.Errorf("Match(%q) = %t, want %t (marker %s)", tc.message, got, tc.want, m)
+			}
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := New("", "(", ""); err == nil {
+		t.Error("New() with invalid regex returned no error")
+	}
+	if _, err := New("", "", "Ult Bump"); err == nil {
+		t.Error("New() with invalid trailer returned no error")
+	}
+}
@@ -2,13 +2,21 @@ package cloudsql
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 
 	_ "github.com/lib/pq"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/logging"
 )
 
-// ConnectWithConnector connects to Cloud SQL instance
+var (
+	logger = logging.New("cloudsql")
+)
+
+// ConnectWithConnector connects to Cloud SQL instance. Returns an error when
+// one of the ULT_DB_USER, ULT_DB_PASS and ULT_DB_HOST variables is not set.
 func ConnectWithConnector() (*sql.DB, error) {
 	var (
 		dbUser = os.Getenv("ULT_DB_USER")
@@ -17,13 +25,13 @@ func ConnectWithConnector() (*sql.DB, error) {
 	)
 
 	if len(dbUser) == 0 {
-		panic("ULT_DB_USER variable must not be empty")
+		return nil, errors.New("ULT_DB_USER variable must not be empty")
 	}
 	if len(dbPwd) == 0 {
-		panic("ULT_DB_PASS variable must not be empty")
+		return nil, errors.New("ULT_DB_PASS variable must not be empty")
 	}
 	if len(dbHost) == 0 {
-		panic("ULT_DB_HOST variable must not be empty")
+		return nil, errors.New("ULT_DB_HOST variable must not be empty")
 	}
 
 	databaseURL := fmt.Sprintf("postgresql://%s:%s@%s?sslmode=require", dbUser, dbPwd, dbHost)
@@ -48,8 +56,8 @@ func GenerateTables(db *sql.DB) error {
 		name TEXT NOT NULL,
 		email TEXT NOT NULL UNIQUE
 	);`
-	fmt.Printf("creating table 'assignees': %s\n\n", createAssigneesTableQuery)
-	_, err := db.Exec(createAssigneesTableQuery)
+	logger.Info("creating table 'assignees'")
+	_, err := execQuery(db, createAssigneesTableQuery)
 	if err != nil {
 		return fmt.Errorf("failed to create assignees table: %w", err)
 	}
@@ -61,17 +69,29 @@ func GenerateTables(db *sql.DB) error {
 		year INTEGER NOT NULL,
 		major INTEGER NOT NULL,
 		minor INTEGER NOT NULL,
-    UNIQUE (year, major, minor)
+		pre_release TEXT NOT NULL DEFAULT ''
 	);`
-	fmt.Printf("creating table 'versions': %s\n\n", createVersionsTableQuery)
-	_, err = db.Exec(createVersionsTableQuery)
+	logger.Info("creating table 'versions'")
+	_, err = execQuery(db, createVersionsTableQuery)
 	if err != nil {
 		return fmt.Errorf("failed to create versions table: %w", err)
 	}
 
+	// the versions are unique by idx_versions_unique, tables created before pre-releases were
+	// supported are unique only by year, major and minor
+	migrateVersionsPreReleaseQuery := `
+	ALTER TABLE versions ADD COLUMN IF NOT EXISTS pre_release TEXT NOT NULL DEFAULT '';
+	ALTER TABLE versions DROP CONSTRAINT IF EXISTS versions_year_major_minor_key;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_versions_unique ON versions (year, major, minor, pre_release);`
+	logger.Info("migrating table 'versions'")
+	_, err = execQuery(db, migrateVersionsPreReleaseQuery)
+	if err != nil {
+		return fmt.Errorf("failed to migrate versions table: %w", err)
+	}
+
 	idx_versions_sort := `CREATE INDEX IF NOT EXISTS idx_versions_sort ON versions (year DESC, major DESC, minor DESC);`
-	fmt.Printf("creating index 'idx_versions_sort': %s\n\n", idx_versions_sort)
-	_, err = db.Exec(idx_versions_sort)
+	logger.Info("creating index 'idx_versions_sort'")
+	_, err = execQuery(db, idx_versions_sort)
 	if err != nil {
 		return fmt.Errorf("failed to create index: %w", err)
 	}
@@ -84,24 +104,63 @@ func GenerateTables(db *sql.DB) error {
 		commit TEXT,
 		date TIMESTAMPTZ NOT NULL,
 		issue_tracker_id INTEGER NOT NULL,
+		issue_key TEXT NOT NULL DEFAULT '',
 		version_id INTEGER NOT NULL,
 		bump INTEGER NOT NULL DEFAULT 0,
 		PRIMARY KEY (version_id, bump),
 		FOREIGN KEY (version_id) REFERENCES versions(id),
 		FOREIGN KEY (assignee_id) REFERENCES assignees(id)
 	);`
-	fmt.Printf("creating table 'releases': %s\n\n", createTableReleasesQuery)
-	_, err = db.Exec(createTableReleasesQuery)
+	logger.Info("creating table 'releases'")
+	_, err = execQuery(db, createTableReleasesQuery)
 	if err != nil {
 		return fmt.Errorf("failed to create releases table: %w", err)
 	}
 
+	// releases created before issue keys only stored the number of the app-0000 branches
+	migrateReleasesIssueKeyQuery := `
+	ALTER TABLE releases ADD COLUMN IF NOT EXISTS issue_key TEXT NOT NULL DEFAULT '';
+	UPDATE releases SET issue_key = 'APP-' || issue_tracker_id WHERE issue_key = '' AND issue_tracker_id > 0;`
+	logger.Info("migrating table 'releases'")
+	_, err = execQuery(db, migrateReleasesIssueKeyQuery)
+	if err != nil {
+		return fmt.Errorf("failed to migrate releases table: %w", err)
+	}
+
 	idx_releases_bump := `CREATE INDEX IF NOT EXISTS idx_releases_bump ON releases(bump);`
-	fmt.Printf("creating index 'idx_releases_bump': %s\n\n", idx_releases_bump)
-	_, err = db.Exec(idx_releases_bump)
+	logger.Info("creating index 'idx_releases_bump'")
+	_, err = execQuery(db, idx_releases_bump)
 	if err != nil {
 		return fmt.Errorf("failed to create index: %w", err)
 	}
 
+	createBuildReservationsTableQuery := `
+	CREATE TABLE IF NOT EXISTS build_reservations (
+		version_id INTEGER NOT NULL,
+		bump INTEGER NOT NULL,
+		pipeline TEXT NOT NULL DEFAULT '',
+		commit TEXT NOT NULL DEFAULT '',
+		reserved_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		expires_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (version_id, bump),
+		FOREIGN KEY (version_id) REFERENCES versions(id)
+	);`
+	logger.Info("creating table 'build_reservations'")
+	_, err = execQuery(db, createBuildReservationsTableQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create build reservations table: %w", err)
+	}
+
 	return nil
 }
+
+// execQuery executes a schema changing query, in dry-run mode it is skipped.
+func execQuery(db *sql.DB, query string) (sql.Result, error) {
+	if dryrun.Enabled() {
+		dryrun.Printf("execute query: %s", query)
+		return nil, nil
+	}
+
+	logger.Debug("executing query", "query", query)
+	return db.Exec(query)
+}
@@ -0,0 +1,26 @@
+package cloudsql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConnectWithConnectorMissingVariables(t *testing.T) {
+	tt := map[string]map[string]string{
+		"ULT_DB_USER": {"ULT_DB_USER": "", "ULT_DB_PASS": "secret", "ULT_DB_HOST": "localhost"},
+		"ULT_DB_PASS": {"ULT_DB_USER": "ult", "ULT_DB_PASS": "", "ULT_DB_HOST": "localhost"},
+		"ULT_DB_HOST": {"ULT_DB_USER": "ult", "ULT_DB_PASS": "secret", "ULT_DB_HOST": ""},
+	}
+
+	for missing, env := range tt {
+		t.Run(missing, func(t *testing.T) {
+			for key, value := range env {
+				t.Setenv(key, value)
+			}
+			db, err := ConnectWithConnector()
+			if err == nil || !strings.Contains(err.Error(), missing) {
+				t.Errorf("ConnectWithConnector() = %v, %v, want an error naming %s", db, err, missing)
+			}
+		})
+	}
+}
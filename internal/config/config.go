@@ -0,0 +1,222 @@
+// Package config loads the layered ult configuration files: the project level
+// .ult.yaml (searched from the working directory upwards) and the user level
+// config.yaml stored under the XDG config directory.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ProjectFileName is the name of the project level configuration file.
+	ProjectFileName = ".ult.yaml"
+	// UserFileName is the name of the user level configuration file inside
+	// the ult folder of the XDG config directory.
+	UserFileName = "config.yaml"
+)
+
+// Layer is a single configuration file that was loaded from disk.
+type Layer struct {
+	// Name describes the layer, e.g. "project file" or "user file".
+	Name string
+	// Path is the absolute path of the file the layer was read from.
+	Path   string
+	values map[string]any
+}
+
+// Config holds all loaded layers ordered from the highest precedence
+// (project file) to the lowest (user file).
+type Config struct {
+	Layers []*Layer
+}
+
+// Load reads the project file (when projectPath is empty it is searched from
+// the working directory upwards) and the user file. A projectPath that does
+// not exist is an error, the other missing files are not: an empty Config is
+// returned when none of them exists.
+func Load(projectPath string) (*Config, error) {
+	if len(projectPath) > 0 {
+		if _, err := os.Stat(projectPath); err != nil {
+			return nil, fmt.Errorf("reading config file %s: %w", projectPath, err)
+		}
+	} else {
+		found, err := FindProjectFile()
+		if err != nil {
+			return nil, err
+		}
+		projectPath = found
+	}
+
+	userPath, err := UserFilePath()
+	if err != nil {
+		userPath = ""
+	}
+
+	return LoadFrom(projectPath, userPath)
+}
+
+// LoadFrom reads the configuration layers from the given paths. Empty paths
+// and files that do not exist are skipped.
+func LoadFrom(projectPath, userPath string) (*Config, error) {
+	cfg := &Config{}
+
+	for _, l := range []struct{ name, path string }{
+		{"project file", projectPath},
+		{"user file", userPath},
+	} {
+		if len(l.path) == 0 {
+			continue
+		}
+
+		layer, err := readLayer(l.name, l.path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.Layers = append(cfg.Layers, layer)
+	}
+
+	return cfg, nil
+}
+
+// FindProjectFile walks from the working directory up to the filesystem root
+// looking for a .ult.yaml file. Returns an empty string if none was found.
+func FindProjectFile() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("getting working directory: %w", err)
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// UserFilePath returns the path of the user level configuration file,
+// $XDG_CONFIG_HOME/ult/config.yaml falling back to ~/.config/ult/config.yaml.
+func UserFilePath() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if len(base) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("getting user home directory: %w", err)
+		}
+		base = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(base, "ult", UserFileName), nil
+}
+
+func readLayer(name, path string) (*Layer, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{}
+	if err := yaml.Unmarshal(contents, &values); err != nil {
+		return nil, fmt.Errorf("parsing config file (%s): %w", path, err)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+
+	return &Layer{Name: name, Path: abs, values: values}, nil
+}
+
+// Lookup returns the raw value stored under key in the first layer that
+// defines it. Nested keys are addressed with dots, e.g. "gitlab.url".
+func (c *Config) Lookup(key string) (any, *Layer, bool) {
+	if c == nil {
+		return nil, nil, false
+	}
+
+	for _, layer := range c.Layers {
+		if value, ok := layer.lookup(key); ok {
+			return value, layer, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+// String returns the value stored under key formatted as a string. Lists are
+// joined with commas.
+func (c *Config) String(key string) (string, *Layer, bool) {
+	value, layer, ok := c.Lookup(key)
+	if !ok {
+		return "", nil, false
+	}
+
+	return format(value), layer, true
+}
+
+// Decode decodes the value stored under key in the first layer that defines
+// it into out. Returns the layer used or nil when no layer defines the key.
+func (c *Config) Decode(key string, out any) (*Layer, error) {
+	value, layer, ok := c.Lookup(key)
+	if !ok {
+		return nil, nil
+	}
+
+	raw, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encoding config key (%s): %w", key, err)
+	}
+	if err := yaml.Unmarshal(raw, out); err != nil {
+		return nil, fmt.Errorf("decoding config key (%s) from %s: %w", key, layer.Path, err)
+	}
+
+	return layer, nil
+}
+
+func (l *Layer) lookup(key string) (any, bool) {
+	var current any = l.values
+	for _, part := range strings.Split(key, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, current != nil
+}
+
+func format(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, format(item))
+		}
+		return strings.Join(parts, ",")
+	}
+
+	return fmt.Sprint(value)
+}
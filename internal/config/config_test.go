@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+	return path
+}
+
+func TestLoadFromPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	project := writeFile(t, dir, ProjectFileName, "project-id: \"123\"\ngroups:\n  - qa\n  - devs\n")
+	user := writeFile(t, dir, UserFileName, "project-id: \"999\"\ntoken: secret\ngitlab:\n  url: https://git.example.com\n")
+
+	cfg, err := LoadFrom(project, user)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+
+	tt := []struct {
+		key       string
+		want      string
+		wantLayer string
+	}{
+		{key: "project-id", want: "123", wantLayer: "project file"},
+		{key: "token", want: "secret", wantLayer: "user file"},
+		{key: "groups", want: "qa,devs", wantLayer: "project file"},
+		{key: "gitlab.url", want: "https://git.example.com", wantLayer: "user file"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.key, func(t *testing.T) {
+			got, layer, ok := cfg.String(tc.key)
+			if !ok {
+				t.Fatalf("String(%q) not found", tc.key)
+			}
+			if got != tc.want {
+				t.Errorf("String(%q) = %q, want %q", tc.key, got, tc.want)
+			}
+			if layer.Name != tc.wantLayer {
+				t.Errorf("String(%q) layer = %q, want %q", tc.key, layer.Name, tc.wantLayer)
+			}
+		})
+	}
+
+	if _, _, ok := cfg.String("missing"); ok {
+		t.Error("String(\"missing\") was expected to not be found")
+	}
+}
+
+func TestLoadFromMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := LoadFrom(filepath.Join(dir, ProjectFileName), "")
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if len(cfg.Layers) != 0 {
+		t.Errorf("LoadFrom() layers = %d, want 0", len(cfg.Layers))
+	}
+}
+
+func TestLoadMissingExplicitFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+
+	if _, err := Load(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load(%q) error = %v, want os.ErrNotExist", path, err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	dir := t.TempDir()
+	project := writeFile(t, dir, ProjectFileName, "section:\n  name: qa\n  keep: 5\n")
+
+	cfg, err := LoadFrom(project, "")
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+
+	var got struct {
+		Name string `yaml:"name"`
+		Keep int    `yaml:"keep"`
+	}
+	layer, err := cfg.Decode("section", &got)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if layer == nil || got.Name != "qa" || got.Keep != 5 {
+		t.Errorf("Decode() = %+v (layer %v), want {qa 5}", got, layer)
+	}
+}
@@ -1,35 +1,310 @@
 package core
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/urfave/cli/v3"
+	"ulist.app/ult/internal/config"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/output"
+	"ulist.app/ult/internal/version"
 )
 
 const (
-	VerboseFlag   = "verbose"
-	TokenFlag     = "token"
-	ProjectIDFlag = "project-id"
+	ConfigFlag        = "config"
+	VerboseFlag       = "verbose"
+	QuietFlag         = "quiet"
+	LogFormatFlag     = "log-format"
+	DryRunFlag        = "dry-run"
+	OutputFlag        = "output"
+	VersionSchemeFlag = "version-scheme"
+	TokenFlag         = "token"
+	ProjectIDFlag     = "project-id"
+	CredentialsFlag   = "credentials"
+	JSONKeyFlag       = "json-key"
+	AppFlag           = "app"
+	PlayPackageFlag   = "play-package"
+	GroupsFlag        = "groups"
+	GitlabURLFlag     = "gitlab-url"
+	CAFileFlag        = "ca-file"
+	PipelineFlag      = "pipeline"
+	CommitSHAFlag     = "commit-sha"
+	BranchFlag        = "branch"
+	BackendFlag       = "backend"
+	SignFlag          = "sign"
+	SigningKeyFlag    = "signing-key"
 )
 
-// GetToken extracts the authentication token from command line arguments.
-// It retrieves the token value using the TokenFlag and validates that it is not empty.
+// Well-known environment variables predefined by GitLab CI and Google tooling.
+const (
+	EnvCIProjectID                  = "CI_PROJECT_ID"
+	EnvCIJobToken                   = "CI_JOB_TOKEN"
+	EnvCIServerURL                  = "CI_SERVER_URL"
+	EnvCIServerTLSCAFile            = "CI_SERVER_TLS_CA_FILE"
+	EnvCIPipelineURL                = "CI_PIPELINE_URL"
+	EnvCICommitSHA                  = "CI_COMMIT_SHA"
+	EnvCICommitBranch               = "CI_COMMIT_BRANCH"
+	EnvCIMergeRequestSourceBranch   = "CI_MERGE_REQUEST_SOURCE_BRANCH_NAME"
+	EnvGoogleApplicationCredentials = "GOOGLE_APPLICATION_CREDENTIALS"
+)
+
+var (
+	logger = logging.New("core")
+)
+
+// Source describes where a resolved setting value came from.
+type Source string
+
+const (
+	SourceUnset       Source = "unset"
+	SourceFlag        Source = "flag"
+	SourceEnv         Source = "env"
+	SourceProjectFile Source = "project file"
+	SourceUserFile    Source = "user file"
+)
+
+// Setting is a value that can be provided by flag, environment variable or
+// configuration file. Name is both the flag name and the config file key.
+type Setting struct {
+	Name    string
+	EnvVars []string
+	Secret  bool
+}
+
+// Settings lists every setting shared between commands, it is used to
+// print the effective configuration.
+var Settings = []Setting{
+	{Name: VerboseFlag, EnvVars: []string{"ULT_VERBOSE"}},
+	{Name: QuietFlag, EnvVars: []string{"ULT_QUIET"}},
+	{Name: LogFormatFlag, EnvVars: []string{"ULT_LOG_FORMAT"}},
+	{Name: DryRunFlag, EnvVars: []string{"ULT_DRY_RUN"}},
+	{Name: OutputFlag, EnvVars: []string{"ULT_OUTPUT"}},
+	{Name: VersionSchemeFlag, EnvVars: []string{"ULT_VERSION_SCHEME"}},
+	{Name: TokenFlag, EnvVars: []string{"ULT_TOKEN", EnvCIJobToken}, Secret: true},
+	{Name: ProjectIDFlag, EnvVars: []string{"ULT_PROJECT_ID", EnvCIProjectID}},
+	{Name: GitlabURLFlag, EnvVars: []string{"ULT_GITLAB_URL", EnvCIServerURL}},
+	{Name: CAFileFlag, EnvVars: []string{"ULT_CA_FILE", EnvCIServerTLSCAFile}},
+	{Name: CredentialsFlag, EnvVars: []string{"ULT_CREDENTIALS", EnvGoogleApplicationCredentials}},
+	{Name: JSONKeyFlag, EnvVars: []string{"ULT_JSON_KEY", EnvGoogleApplicationCredentials}},
+	{Name: AppFlag, EnvVars: []string{"ULT_APP"}},
+	{Name: PlayPackageFlag, EnvVars: []string{"ULT_PLAY_PACKAGE"}},
+	{Name: GroupsFlag, EnvVars: []string{"ULT_GROUPS"}},
+	{Name: PipelineFlag, EnvVars: []string{"ULT_PIPELINE", EnvCIPipelineURL}},
+	{Name: CommitSHAFlag, EnvVars: []string{"ULT_COMMIT_SHA", EnvCICommitSHA}},
+	{Name: BranchFlag, EnvVars: []string{"ULT_BRANCH", EnvCIMergeRequestSourceBranch, EnvCICommitBranch}},
+	{Name: BackendFlag, EnvVars: []string{"ULT_BACKEND"}},
+	{Name: SignFlag, EnvVars: []string{"ULT_SIGN"}},
+	{Name: SigningKeyFlag, EnvVars: []string{"ULT_SIGNING_KEY"}},
+}
+
+// Resolved is a setting value together with where it was read from.
+// Origin holds the environment variable name or the config file path.
+type Resolved struct {
+	Setting
+	Value  string
+	Source Source
+	Origin string
+}
+
+var cfg = &config.Config{}
+
+// Setup is the root command Before hook. It loads the configuration files
+// and applies the global settings shared by every command.
+func Setup(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	ctx, err := LoadConfig(ctx, cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	logFormat, err := logging.ParseFormat(GetString(cmd, LogFormatFlag))
+	if err != nil {
+		return ctx, err
+	}
+	logging.Setup(logging.Options{
+		Verbose: GetBool(cmd, VerboseFlag),
+		Quiet:   GetBool(cmd, QuietFlag),
+		Format:  logFormat,
+	})
+
+	format, err := output.ParseFormat(GetString(cmd, OutputFlag))
+	if err != nil {
+		return ctx, err
+	}
+	output.Set(format)
+
+	scheme, err := version.SchemeByName(GetString(cmd, VersionSchemeFlag))
+	if err != nil {
+		return ctx, err
+	}
+	version.SetDefault(scheme)
+
+	if err := git.SetSigning(git.Signing{Format: GetString(cmd, SignFlag), Key: GetString(cmd, SigningKeyFlag)}); err != nil {
+		return ctx, err
+	}
+
+	dryrun.Set(GetBool(cmd, DryRunFlag))
+	// keep stdout reserved for the JSON/YAML result
+	if output.IsMachineReadable() {
+		dryrun.SetOutput(os.Stderr)
+	}
+
+	return ctx, nil
+}
+
+// LoadConfig reads the project and user configuration files so every
+// command can resolve settings from them.
+func LoadConfig(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	loaded, err := config.Load(cmd.String(ConfigFlag))
+	if err != nil {
+		return ctx, fmt.Errorf("loading configuration: %w", err)
+	}
+
+	cfg = loaded
+	return ctx, nil
+}
+
+// Config returns the configuration loaded by LoadConfig.
+func Config() *config.Config {
+	return cfg
+}
+
+// Lookup resolves a setting with the precedence flag > env > project file > user file.
+// Environment variables of a setting are checked in the order they are declared,
+// settings that are not part of Settings use the environment variable ULT_<NAME>.
+// The resolved source is logged at debug level (--verbose) to help debugging pipelines.
+func Lookup(cmd *cli.Command, name string) Resolved {
+	resolved := resolve(cmd, name)
+	logger.Debug("resolved setting",
+		"name", name,
+		"source", resolved.Source,
+		"origin", resolved.Origin,
+	)
+	return resolved
+}
+
+func resolve(cmd *cli.Command, name string) Resolved {
+	setting := findSetting(name)
+	resolved := Resolved{Setting: setting, Source: SourceUnset}
+
+	if cmd.IsSet(name) {
+		resolved.Value = flagValue(cmd, name)
+		resolved.Source = SourceFlag
+		return resolved
+	}
+
+	for _, env := range setting.EnvVars {
+		if value, ok := os.LookupEnv(env); ok && len(value) > 0 {
+			resolved.Value = value
+			resolved.Source = SourceEnv
+			resolved.Origin = env
+			return resolved
+		}
+	}
+
+	if value, layer, ok := cfg.String(name); ok {
+		resolved.Value = value
+		resolved.Source = layerSource(layer)
+		resolved.Origin = layer.Path
+		return resolved
+	}
+
+	// keep flag defaults working when nothing else provides a value
+	resolved.Value = flagValue(cmd, name)
+	return resolved
+}
+
+// GetString returns the resolved value of the named setting.
+func GetString(cmd *cli.Command, name string) string {
+	return Lookup(cmd, name).Value
+}
+
+// GetStringSlice returns the resolved value of the named setting split by commas.
+func GetStringSlice(cmd *cli.Command, name string) []string {
+	value := GetString(cmd, name)
+	if len(value) == 0 {
+		return nil
+	}
+
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// GetBool returns the resolved value of the named setting as a boolean.
+// Invalid values are reported as false.
+func GetBool(cmd *cli.Command, name string) bool {
+	value, err := strconv.ParseBool(GetString(cmd, name))
+	return err == nil && value
+}
+
+// GetToken extracts the authentication token from the command line, environment
+// or configuration files and validates that it is not empty.
 // Returns the token string if present, otherwise returns an error with usage instructions.
 func GetToken(cmd *cli.Command) (string, error) {
-	token := cmd.String(TokenFlag)
+	token := GetString(cmd, TokenFlag)
 	if len(token) == 0 {
-		return "", errors.New("Argument 'token' not found but is required.\nUsage: '--token=yourtokenhere'")
+		return "", errors.New("Argument 'token' not found but is required.\nUsage: '--token=yourtokenhere' (or 'token' in .ult.yaml)")
 	}
 	return token, nil
 }
 
-// GetProjectID extracts the project ID from command line arguments.
-// It retrieves the project ID value using the ProjectIDFlag and validates that it is not empty.
+// GetProjectID extracts the project ID from the command line, environment
+// or configuration files and validates that it is not empty.
 // Returns the project ID string if present, otherwise returns an error with usage instructions.
 func GetProjectID(cmd *cli.Command) (string, error) {
-	projectID := cmd.String(ProjectIDFlag)
+	projectID := GetString(cmd, ProjectIDFlag)
 	if len(projectID) == 0 {
-		return "", errors.New("Argument 'project-id' not found but is required.\nUsage: '--project-id=0000000' or '--id=0000000'")
+		return "", errors.New("Argument 'project-id' not found but is required.\nUsage: '--project-id=0000000' or '--id=0000000' (or 'project-id' in .ult.yaml)")
 	}
 	return projectID, nil
 }
+
+// GetRequiredString resolves the named setting and returns an error with
+// usage instructions when it is empty.
+func GetRequiredString(cmd *cli.Command, name string) (string, error) {
+	value := GetString(cmd, name)
+	if len(value) == 0 {
+		return "", fmt.Errorf("Argument '%s' not found but is required.\nUsage: '--%s=value' (or '%s' in .ult.yaml)", name, name, name)
+	}
+	return value, nil
+}
+
+func findSetting(name string) Setting {
+	for _, s := range Settings {
+		if s.Name == name {
+			return s
+		}
+	}
+
+	env := "ULT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	return Setting{Name: name, EnvVars: []string{env}}
+}
+
+func flagValue(cmd *cli.Command, name string) string {
+	switch v := cmd.Value(name).(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func layerSource(layer *config.Layer) Source {
+	if layer.Name == string(SourceUserFile) {
+		return SourceUserFile
+	}
+	return SourceProjectFile
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/urfave/cli/v3"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// NewGitlabClient creates a GitLab API client from the resolved token, GitLab
+// URL and CA bundle settings, every command talking to GitLab must use it so
+// self-managed instances are supported. A token read from CI_JOB_TOKEN
+// authenticates as a CI job token instead of a private token.
+func NewGitlabClient(cmd *cli.Command) (*gitlab.Client, error) {
+	if _, err := GetToken(cmd); err != nil {
+		return nil, err
+	}
+	token := Lookup(cmd, TokenFlag)
+
+	opts := []gitlab.ClientOptionFunc{}
+	if baseURL := GetString(cmd, GitlabURLFlag); len(baseURL) > 0 {
+		logger.Info("using custom gitlab instance", "url", baseURL)
+		opts = append(opts, gitlab.WithBaseURL(baseURL))
+	}
+
+	if caFile := GetString(cmd, CAFileFlag); len(caFile) > 0 {
+		httpClient, err := newHTTPClientWithCA(caFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, gitlab.WithHTTPClient(httpClient))
+	}
+
+	var client *gitlab.Client
+	var err error
+	if token.Source == SourceEnv && token.Origin == EnvCIJobToken {
+		client, err = gitlab.NewJobClient(token.Value, opts...)
+	} else {
+		client, err = gitlab.NewClient(token.Value, opts...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating gitlab client: %w", err)
+	}
+
+	return client, nil
+}
+
+// newHTTPClientWithCA returns an http client trusting the certificates in the
+// given PEM file on top of the system certificate pool.
+func newHTTPClientWithCA(path string) (*http.Client, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA bundle (%s): %w", path, err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(contents) {
+		return nil, fmt.Errorf("no valid PEM certificates found in CA bundle (%s)", path)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"github.com/urfave/cli/v3"
+	"ulist.app/ult/internal/git"
+)
+
+// RepositoryBackend returns the backend selected with --backend, or the
+// fallback of the command when it is not set.
+func RepositoryBackend(cmd *cli.Command, fallback string) string {
+	if backend := GetString(cmd, BackendFlag); len(backend) > 0 {
+		return backend
+	}
+	return fallback
+}
+
+// NewRepository returns the repository of the project for the backend: the
+// local clone, or the GitLab project selected by --project-id and --token
+// where the current branch is the resolved --branch.
+func NewRepository(cmd *cli.Command, backend string) (git.Repository, error) {
+	if err := git.ValidateBackend(backend); err != nil {
+		return nil, err
+	}
+	if backend == git.BackendGit {
+		return git.NewLocal(), nil
+	}
+
+	client, err := NewGitlabClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+	projectID, err := GetProjectID(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return git.NewGitlab(client, projectID, GetString(cmd, BranchFlag)), nil
+}
@@ -0,0 +1,35 @@
+// Package dryrun holds the global dry-run switch. When it is enabled every
+// side effecting operation (git writes, GitLab and Firebase requests, database
+// inserts and file writes) prints what it would do instead of doing it.
+package dryrun
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+var (
+	enabled bool
+	output  io.Writer = os.Stdout
+)
+
+// Set enables or disables dry-run mode.
+func Set(on bool) {
+	enabled = on
+}
+
+// Enabled reports whether dry-run mode is on.
+func Enabled() bool {
+	return enabled
+}
+
+// SetOutput sets where skipped operations are printed, defaults to stdout.
+func SetOutput(w io.Writer) {
+	output = w
+}
+
+// Printf prints a description of a skipped operation prefixed with "[dry-run]".
+func Printf(format string, args ...any) {
+	fmt.Fprintf(output, "[dry-run] "+format+"\n", args...)
+}
@@ -2,16 +2,59 @@ package git
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"ulist.app/ult/internal/assignee"
 )
 
+// Commit is a commit read with git log or from the GitLab API. Assignee is
+// the author of the commit, Date the author date.
 type Commit struct {
-	Assignee assignee.Assignee
-	Hash     string
-	Date     time.Time
-	Message  string
+	Assignee   assignee.Assignee
+	Committer  assignee.Assignee
+	Hash       string
+	Parents    []string
+	Date       time.Time
+	CommitDate time.Time
+	// Message is the full message, Subject its first paragraph and Body the rest
+	Message   string
+	Subject   string
+	Body      string
+	Trailers  []Trailer
+	Signature SignatureStatus
+	// Signer is the signer of a signed commit, e.g. the key owner
+	Signer string
+	// WebURL is only known for commits read from GitLab
+	WebURL string
+}
+
+// SignatureStatus is the verification result of the commit signature, it is
+// empty when the signature was not checked (GitLab backend).
+type SignatureStatus string
+
+const (
+	SignatureNone         SignatureStatus = "none"
+	SignatureGood         SignatureStatus = "good"
+	SignatureUntrusted    SignatureStatus = "untrusted"
+	SignatureBad          SignatureStatus = "bad"
+	SignatureExpired      SignatureStatus = "expired"
+	SignatureExpiredKey   SignatureStatus = "expired-key"
+	SignatureRevokedKey   SignatureStatus = "revoked-key"
+	SignatureUnverifiable SignatureStatus = "unverifiable"
+)
+
+// signatureStatuses maps the %G? placeholder of git log to the status.
+var signatureStatuses = map[string]SignatureStatus{
+	"N": SignatureNone,
+	"G": SignatureGood,
+	"U": SignatureUntrusted,
+	"B": SignatureBad,
+	"X": SignatureExpired,
+	"Y": SignatureExpiredKey,
+	"R": SignatureRevokedKey,
+	"E": SignatureUnverifiable,
 }
 
 func (c Commit) String() string {
@@ -24,3 +67,117 @@ func (c Commit) String() string {
 		c.Message,
 	)
 }
+
+// Trailer returns the value of the first trailer with the key, compared
+// case-insensitively like git does.
+func (c Commit) Trailer(key string) (string, bool) {
+	for _, trailer := range c.Trailers {
+		if strings.EqualFold(trailer.Key, key) {
+			return trailer.Value, true
+		}
+	}
+	return "", false
+}
+
+// setMessage fills the message, subject, body and trailers from the full
+// commit message.
+func (c *Commit) setMessage(message string) {
+	c.Message = strings.TrimSpace(message)
+	subject, body, _ := strings.Cut(c.Message, "\n\n")
+	c.Subject = strings.Join(strings.Fields(subject), " ")
+	c.Body = strings.TrimSpace(body)
+	c.Trailers = ParseTrailers(c.Message)
+}
+
+// commitFormat is the git log format read by parseCommits, the fields are
+// separated by NUL and git log -z separates the commits by NUL as well, no
+// field but the message can contain a newline and none can contain a NUL.
+const commitFormat = "%H%x00%P%x00%an%x00%ae%x00%aI%x00%cn%x00%ce%x00%cI%x00%G?%x00%GS%x00%B"
+
+const commitFields = 11
+
+// parseCommits parses the output of git log -z --format=commitFormat.
+func parseCommits(output string) ([]Commit, error) {
+	fields := strings.Split(output, "\x00")
+	// the last commit is terminated by a NUL too
+	if len(fields) > 0 && len(strings.TrimSpace(fields[len(fields)-1])) == 0 {
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields)%commitFields != 0 {
+		return nil, fmt.Errorf("commit with invalid format: %q", output)
+	}
+
+	commits := make([]Commit, 0, len(fields)/commitFields)
+	for i := 0; i < len(fields); i += commitFields {
+		commit, err := parseCommit(fields[i : i+commitFields])
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+func parseCommit(fields []string) (Commit, error) {
+	// git log -z puts the NUL before the next commit but keeps newlines
+	hash := strings.TrimSpace(fields[0])
+	if len(hash) == 0 {
+		return Commit{}, fmt.Errorf("commit with invalid format: %q", strings.Join(fields, "\x00"))
+	}
+
+	date, err := time.Parse(time.RFC3339, fields[4])
+	if err != nil {
+		return Commit{}, fmt.Errorf("not able to parse date (%s): %w", fields[4], err)
+	}
+	commitDate, err := time.Parse(time.RFC3339, fields[7])
+	if err != nil {
+		return Commit{}, fmt.Errorf("not able to parse date (%s): %w", fields[7], err)
+	}
+
+	commit := Commit{
+		Hash:       hash,
+		Parents:    strings.Fields(fields[1]),
+		Assignee:   assignee.Assignee{Name: fields[2], Email: fields[3]},
+		Date:       date,
+		Committer:  assignee.Assignee{Name: fields[5], Email: fields[6]},
+		CommitDate: commitDate,
+		Signature:  signatureStatuses[fields[8]],
+		Signer:     fields[9],
+	}
+	commit.setMessage(fields[10])
+	return commit, nil
+}
+
+// Trailer is a "Key: value" line of the last paragraph of a commit message,
+// e.g. "Signed-off-by: John Doe <john@example.com>".
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+var trailerRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):[ \t]*(.*)$`)
+
+// ParseTrailers returns the trailers of the commit message. Like git, the
+// last paragraph only holds trailers when every line of it is a trailer and
+// it is not the subject.
+func ParseTrailers(message string) []Trailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	trailers := []Trailer{}
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		// folded values continue on lines starting with whitespace
+		if len(trailers) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			trailers[len(trailers)-1].Value += " " + strings.TrimSpace(line)
+			continue
+		}
+		matches := trailerRegex.FindStringSubmatch(line)
+		if matches == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: matches[1], Value: strings.TrimSpace(matches[2])})
+	}
+	return trailers
+}
@@ -5,18 +5,15 @@ package git
 import (
 	"errors"
 	"fmt"
-	"log/slog"
 	"os/exec"
-	"regexp"
-	"strconv"
 	"strings"
-	"time"
 
-	"ulist.app/ult/internal/assignee"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/logging"
 )
 
 var (
-	logger = slog.Default().WithGroup("git")
+	logger = logging.New("git")
 )
 
 // execCommand is a helper function that executes an external command and returns its output.
@@ -49,17 +46,28 @@ func execCommand(name string, args ...string) ([]byte, error) {
 	return output, nil
 }
 
+// execWriteCommand executes a command that changes the local repository or the remote.
+// In dry-run mode the command is only printed and no output is returned.
+func execWriteCommand(name string, args ...string) ([]byte, error) {
+	if dryrun.Enabled() {
+		dryrun.Printf("%s %s", name, strings.Join(args, " "))
+		return nil, nil
+	}
+
+	return execCommand(name, args...)
+}
+
 // CommitChanges stages all modified files and commits them with a standard message.
 // It stages files using 'git add .' and creates a commit with an automated message.
 // Returns an error if either the staging or commit operations fail.
 func CommitChanges() error {
 	logger.Info("Staging all changes")
-	if _, err := execCommand("git", "add", "."); err != nil {
+	if _, err := execWriteCommand("git", "add", "."); err != nil {
 		return fmt.Errorf("staging changes: %w", err)
 	}
 
 	logger.Info("Creating commit")
-	if _, err := execCommand("git", "commit", "-m", "chore: [AUTO] build and deploy script changes"); err != nil {
+	if _, err := execWriteCommand("git", commitArgs("-m", "chore: [AUTO] build and deploy script changes")...); err != nil {
 		return fmt.Errorf("creating commit: %w", err)
 	}
 
@@ -67,33 +75,21 @@ func CommitChanges() error {
 	return nil
 }
 
-// CreateTag creates a Git tag at the current HEAD commit.
-// The tag is created with the --force flag, which will move the tag if it already exists.
-// Returns an error if the tag creation fails.
-func CreateTag(tag string) error {
-	logger.Info("Creating tag", "tag", tag)
-
-	if _, err := execCommand("git", "tag", tag, "--force"); err != nil {
-		return fmt.Errorf("creating tag %q: %w", tag, err)
-	}
-
-	logger.Info("Tag created successfully", "tag", tag)
-	return nil
-}
-
-// PushChanges pushes committed changes and tags to the remote repository.
-// It pushes the current branch to the 'origin' remote and then pushes all tags
-// with the --force flag to override any existing tags.
+// PushChanges pushes committed changes and the tag to the remote repository.
+// It pushes the current branch to the 'origin' remote and then only the given
+// tag, a tag that exists on the remote at another commit is never overwritten.
 // Returns an error if either push operation fails.
-func PushChanges() error {
+func PushChanges(tag string) error {
 	logger.Info("Pushing changes to origin")
-	if _, err := execCommand("git", "push", "--set-upstream", "origin"); err != nil {
+	if _, err := execWriteCommand("git", "push", "--set-upstream", "origin"); err != nil {
 		return fmt.Errorf("pushing to origin: %w", err)
 	}
 
-	logger.Info("Pushing tags")
-	if _, err := execCommand("git", "push", "--tags", "--force"); err != nil {
-		return fmt.Errorf("pushing tags: %w", err)
+	if len(tag) > 0 {
+		logger.Info("Pushing tag", "tag", tag)
+		if _, err := execWriteCommand("git", "push", "origin", "refs/tags/"+tag); err != nil {
+			return fmt.Errorf("pushing tag %q: %w", tag, err)
+		}
 	}
 
 	logger.Info("Changes pushed successfully")
@@ -117,91 +113,186 @@ func GetCurrentBranch() (string, error) {
 	return currentBranch, nil
 }
 
+// GetLatestCommitInfo returns the commit HEAD points to.
 func GetLatestCommitInfo() (*Commit, error) {
 	logger.Info("Getting commit information")
-
-	output, err := execCommand("git", "log", "-1")
-	if err != nil {
-		return nil, fmt.Errorf("getting latest commit info: %w", err)
-	}
-
-	return getCommitInfoFromStdout(string(output))
+	return getCommit("HEAD")
 }
 
+// GetCommitInfo returns the commit the hash, branch or tag points to.
 func GetCommitInfo(hash string) (*Commit, error) {
 	logger.Info("Getting commit information")
 
 	if len(hash) == 0 {
 		return nil, errors.New("Commit hash cannot be empty")
 	}
+	return getCommit(hash)
+}
 
-	output, err := execCommand("git", "log", "-1", hash)
+func getCommit(ref string) (*Commit, error) {
+	output, err := execCommand("git", "log", "-1", "-z", "--format="+commitFormat, ref, "--")
 	if err != nil {
 		return nil, fmt.Errorf("getting commit info: %w", err)
 	}
 
-	return getCommitInfoFromStdout(string(output))
+	commits, err := parseCommits(string(output))
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) != 1 {
+		return nil, fmt.Errorf("commit %s not found", ref)
+	}
+	return &commits[0], nil
 }
 
-func getCommitInfoFromStdout(stdout string) (*Commit, error) {
-	re := regexp.MustCompile(`^commit (.*).*?\nAuthor: (.*?) <(.*)>.*?\nDate:   (.*?)\n\n(.*)`)
-	matches := re.FindStringSubmatch(stdout)
+// GetCommitRange returns the commits reachable from to but not from from
+// (git log from..to) in a single git call, newest first. An empty from
+// returns every commit reachable from to.
+func GetCommitRange(from, to string) ([]Commit, error) {
+	revision := to
+	if len(from) > 0 {
+		revision = from + ".." + to
+	}
 
-	if len(matches) != 6 {
-		return nil, fmt.Errorf("commit with invalid format: %s", stdout)
+	output, err := execCommand("git", "log", "-z", "--format="+commitFormat, revision, "--")
+	if err != nil {
+		return nil, fmt.Errorf("getting commits (%s): %w", revision, err)
 	}
+	return parseCommits(string(output))
+}
 
-	dateStr := matches[4]
-	date, err := time.Parse("Mon Jan 2 15:04:05 2006 -0700", dateStr)
+func HasChanges(filePath string) (bool, error) {
+	output, err := execCommand("git", "diff", filePath)
 	if err != nil {
-		return nil, fmt.Errorf("not able to parse date (%s): %w", dateStr, err)
+		return false, fmt.Errorf("getting diff for file (%s): %w", filePath, err)
 	}
 
-	commit := Commit{
-		Hash:     matches[1],
-		Assignee: assignee.Assignee{Name: matches[2], Email: matches[3]},
-		Date:     date,
-		Message:  strings.TrimSpace(matches[5]),
+	return len(output) != 0, nil
+}
+
+func GetTagFromCommit(hash string) (string, error) {
+	output, err := execCommand("git", "tag", "--points-at", hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tag from given commit hash (%s): %w", hash, err)
 	}
 
-	return &commit, nil
+	return string(output), nil
 }
 
-func GetIssueNumberFromBranch(branch string) (int64, error) {
-	if len(branch) == 0 {
-		return 0, errors.New("branch name cannot be empty")
+// ListTags returns the local tags matching the glob pattern, e.g. "QA-v*".
+func ListTags(pattern string) ([]string, error) {
+	output, err := execCommand("git", "tag", "--list", pattern)
+	if err != nil {
+		return nil, fmt.Errorf("listing tags (%s): %w", pattern, err)
+	}
+
+	tags := []string{}
+	for _, tag := range strings.Split(string(output), "\n") {
+		if tag = strings.TrimSpace(tag); len(tag) > 0 {
+			tags = append(tags, tag)
+		}
 	}
+	return tags, nil
+}
 
-	name := strings.ToLower(branch)
-	re := regexp.MustCompile(`app-(\d*)`)
-	matches := re.FindStringSubmatch(name)
+// GetLatestTag returns the most recent tag reachable from ref, optionally
+// limited to tags matching the glob pattern. Returns an empty string when
+// there is no such tag.
+func GetLatestTag(ref, pattern string) (string, error) {
+	listArgs := []string{"tag", "--merged", ref}
+	if len(pattern) > 0 {
+		listArgs = append(listArgs, "--list", pattern)
+	}
+	tags, err := execCommand("git", listArgs...)
+	if err != nil {
+		return "", fmt.Errorf("listing tags of %s: %w", ref, err)
+	}
+	// git describe fails when there are no tags
+	if len(strings.TrimSpace(string(tags))) == 0 {
+		return "", nil
+	}
 
-	if len(matches) == 0 {
-		return 0, fmt.Errorf("did not found issue number in: %s. branch name must contain 'app-0000' to be valid", name)
+	args := []string{"describe", "--tags", "--abbrev=0"}
+	if len(pattern) > 0 {
+		args = append(args, "--match", pattern)
 	}
+	args = append(args, ref)
 
-	issue, err := strconv.ParseInt(matches[1], 10, 64)
+	output, err := execCommand("git", args...)
 	if err != nil {
-		return 0, fmt.Errorf("not able to convert issue number (%s) to int: %w", matches[1], err)
+		return "", fmt.Errorf("getting latest tag of %s: %w", ref, err)
 	}
 
-	return issue, nil
+	return strings.TrimSpace(string(output)), nil
 }
 
-func HasChanges(filePath string) (bool, error) {
-	output, err := execCommand("git", "diff", filePath)
+// GetCommitMessages returns the full messages of the commits reachable from
+// to but not from from (git log from..to), newest first. An empty from
+// returns every commit reachable from to.
+func GetCommitMessages(from, to string) ([]string, error) {
+	commits, err := GetCommitRange(from, to)
 	if err != nil {
-		return false, fmt.Errorf("getting diff for file (%s): %w", filePath, err)
+		return nil, err
 	}
 
-	return len(output) != 0, nil
+	messages := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		if len(commit.Message) > 0 {
+			messages = append(messages, commit.Message)
+		}
+	}
+	return messages, nil
 }
 
-func GetTagFromCommit(hash string) (string, error) {
-	output, err := execCommand("git", "tag", "--points-at", hash)
+// GetHeadCommit returns the hash of the commit HEAD points to.
+func GetHeadCommit() (string, error) {
+	output, err := execCommand("git", "rev-parse", "HEAD")
 	if err != nil {
-		return "", fmt.Errorf("failed to get tag from given commit hash (%s): %w", hash, err)
+		return "", fmt.Errorf("getting HEAD commit: %w", err)
 	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	return string(output), nil
+// CommitFiles stages and commits only the given files with the message,
+// other staged changes are left out of the commit.
+func CommitFiles(message string, paths ...string) error {
+	logger.Info("Committing files", "paths", paths)
+
+	if _, err := execWriteCommand("git", append([]string{"add", "--"}, paths...)...); err != nil {
+		return fmt.Errorf("staging files: %w", err)
+	}
+	if _, err := execWriteCommand("git", commitArgs(append([]string{"-m", message, "--"}, paths...)...)...); err != nil {
+		return fmt.Errorf("creating commit: %w", err)
+	}
+
+	logger.Info("Files committed successfully")
+	return nil
+}
+
+// DeleteTag deletes a local tag.
+func DeleteTag(tag string) error {
+	if _, err := execWriteCommand("git", "tag", "--delete", tag); err != nil {
+		return fmt.Errorf("deleting tag %q: %w", tag, err)
+	}
+	return nil
+}
+
+// ResetTo moves the current branch and the index to ref keeping the files
+// in the working tree untouched (git reset --mixed).
+func ResetTo(ref string) error {
+	if _, err := execWriteCommand("git", "reset", "--quiet", ref); err != nil {
+		return fmt.Errorf("resetting to %s: %w", ref, err)
+	}
+	return nil
+}
+
+// PushRefs pushes the refspecs to the remote atomically, either every ref
+// is updated or none.
+func PushRefs(remote string, refspecs ...string) error {
+	logger.Info("Pushing refs", "remote", remote, "refspecs", refspecs)
+
+	if _, err := execWriteCommand("git", append([]string{"push", "--atomic", remote}, refspecs...)...); err != nil {
+		return fmt.Errorf("pushing to %s: %w", remote, err)
+	}
+	return nil
 }
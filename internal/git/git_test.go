@@ -1,24 +1,37 @@
 package git
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"ulist.app/ult/internal/assignee"
 )
 
-func TestGetCommitInfoFromStdout_StandartCommit(t *testing.T) {
-	input := `commit 3a4f5d6e7g8h9i0j1k2l3m4n5o6p7q8r9s0
-Author: John Doe <john@example.com>
-Date:   Wed Apr 10 15:30:45 2024 -0300
+// logOutput builds the output of git log -z --format=commitFormat.
+func logOutput(commits ...[]string) string {
+	output := ""
+	for _, fields := range commits {
+		output += strings.Join(fields, "\x00") + "\x00"
+	}
+	return output
+}
 
-	Add new feature implementation
-`
+func TestParseCommits_StandardCommit(t *testing.T) {
+	input := logOutput([]string{
+		"3a4f5d6e7g8h9i0j1k2l3m4n5o6p7q8r9s0", "0a1b2c3d",
+		"John Doe", "john@example.com", "2024-04-10T15:30:45-03:00",
+		"GitLab", "noreply@gitlab.com", "2024-04-10T16:00:00-03:00",
+		"N", "",
+		"Add new feature implementation\n",
+	})
 
-	got, err := getCommitInfoFromStdout(input)
+	got, err := parseCommits(input)
 	if err != nil {
-		t.Errorf("getCommitInfoFromStdout() error = %v", err)
-		return
+		t.Fatalf("parseCommits() error = %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("parseCommits() returned %d commits, want 1", len(got))
 	}
 
 	expected := &Commit{
@@ -27,48 +40,81 @@ Date:   Wed Apr 10 15:30:45 2024 -0300
 		Date:     time.Date(2024, time.April, 10, 15, 30, 45, 0, time.FixedZone("", -3*60*60)),
 		Message:  "Add new feature implementation",
 	}
+	assertCommitEqual(t, &got[0], expected)
 
-	assertCommitEqual(t, got, expected)
+	if got[0].Committer != (assignee.Assignee{Name: "GitLab", Email: "noreply@gitlab.com"}) {
+		t.Errorf("Committer = %v", got[0].Committer)
+	}
+	if len(got[0].Parents) != 1 || got[0].Parents[0] != "0a1b2c3d" {
+		t.Errorf("Parents = %v, want [0a1b2c3d]", got[0].Parents)
+	}
+	if got[0].Signature != SignatureNone {
+		t.Errorf("Signature = %q, want %q", got[0].Signature, SignatureNone)
+	}
 }
 
-func TestGetCommitInfoFromStdout_MultiLineMessage(t *testing.T) {
-	input := `commit a1b2c3d4e5f6g7h8i9j0k1l2m3n4o5p6
-Author: Jane Smith <jane@company.com>
-Date:   Tue Mar 5 09:15:30 2024 +0000
-
-	Refactor database module
-	
-	- Improved connection handling
-	- Added transaction support
-	- Fixed memory leaks
-`
-
-	got, err := getCommitInfoFromStdout(input)
+func TestParseCommits_MultiLineMessage(t *testing.T) {
+	message := "Refactor database module\n" +
+		"\n" +
+		"- Improved connection handling\n" +
+		"- Added transaction support\n" +
+		"\n" +
+		"Issue: APP-123\n" +
+		"Co-authored-by: John Doe <john@example.com>\n"
+	input := logOutput([]string{
+		"a1b2c3d4e5f6g7h8i9j0k1l2m3n4o5p6", "0a1b2c3d 4e5f6a7b",
+		"Jane Smith", "jane@company.com", "2024-03-05T09:15:30+00:00",
+		"Jane Smith", "jane@company.com", "2024-03-05T09:15:30+00:00",
+		"G", "Jane Smith <jane@company.com>",
+		message,
+	})
+
+	got, err := parseCommits(input)
 	if err != nil {
-		t.Errorf("getCommitInfoFromStdout() error = %v", err)
-		return
+		t.Fatalf("parseCommits() error = %v", err)
 	}
 
 	expected := &Commit{
 		Hash:     "a1b2c3d4e5f6g7h8i9j0k1l2m3n4o5p6",
 		Assignee: assignee.Assignee{Name: "Jane Smith", Email: "jane@company.com"},
 		Date:     time.Date(2024, time.March, 5, 9, 15, 30, 0, time.UTC),
-		Message:  "Refactor database module",
+		Message:  strings.TrimSpace(message),
 	}
+	assertCommitEqual(t, &got[0], expected)
 
-	assertCommitEqual(t, got, expected)
+	commit := got[0]
+	if commit.Subject != "Refactor database module" {
+		t.Errorf("Subject = %q", commit.Subject)
+	}
+	if !strings.HasPrefix(commit.Body, "- Improved connection handling") {
+		t.Errorf("Body = %q", commit.Body)
+	}
+	if issue, ok := commit.Trailer("issue"); !ok || issue != "APP-123" {
+		t.Errorf("Trailer(issue) = %q, %t, want APP-123", issue, ok)
+	}
+	if len(commit.Trailers) != 2 {
+		t.Errorf("Trailers = %v, want 2 trailers", commit.Trailers)
+	}
+	if len(commit.Parents) != 2 {
+		t.Errorf("Parents = %v, want a merge commit", commit.Parents)
+	}
+	if commit.Signature != SignatureGood || commit.Signer != "Jane Smith <jane@company.com>" {
+		t.Errorf("Signature = %q by %q", commit.Signature, commit.Signer)
+	}
 }
 
-func TestGetCommitInfoFromStdout_MinimalCommit(t *testing.T) {
-	input := `commit b5c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7
-Author: CI Bot <ci@example.com>
-Date:   Mon Jan 1 00:00:00 2024 +0000
+func TestParseCommits_MinimalCommit(t *testing.T) {
+	input := logOutput([]string{
+		"b5c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7", "",
+		"CI Bot", "ci@example.com", "2024-01-01T00:00:00+00:00",
+		"CI Bot", "ci@example.com", "2024-01-01T00:00:00+00:00",
+		"N", "",
+		"",
+	})
 
-`
-	got, err := getCommitInfoFromStdout(input)
+	got, err := parseCommits(input)
 	if err != nil {
-		t.Errorf("getCommitInfoFromStdout() error = %v", err)
-		return
+		t.Fatalf("parseCommits() error = %v", err)
 	}
 
 	expected := &Commit{
@@ -77,14 +123,47 @@ Date:   Mon Jan 1 00:00:00 2024 +0000
 		Date:     time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
 		Message:  "",
 	}
+	assertCommitEqual(t, &got[0], expected)
+
+	if len(got[0].Parents) != 0 {
+		t.Errorf("Parents = %v, want a root commit", got[0].Parents)
+	}
+}
+
+func TestParseCommits_Range(t *testing.T) {
+	commit := func(hash, message string) []string {
+		return []string{
+			hash, "",
+			"CI Bot", "ci@example.com", "2024-01-01T00:00:00+00:00",
+			"CI Bot", "ci@example.com", "2024-01-01T00:00:00+00:00",
+			"N", "",
+			message,
+		}
+	}
+	// git log -z separates commits with a NUL following the message newline
+	input := logOutput(commit("c3", "fix: c\n"), commit("c2", "feat: b\n\nbody\n"), commit("c1", "chore: a\n"))
 
-	assertCommitEqual(t, got, expected)
+	got, err := parseCommits(input)
+	if err != nil {
+		t.Fatalf("parseCommits() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("parseCommits() returned %d commits, want 3", len(got))
+	}
+	for i, want := range []string{"c3", "c2", "c1"} {
+		if got[i].Hash != want {
+			t.Errorf("commit %d = %q, want %q", i, got[i].Hash, want)
+		}
+	}
+	if got[1].Subject != "feat: b" || got[1].Body != "body" {
+		t.Errorf("commit c2 subject = %q, body = %q", got[1].Subject, got[1].Body)
+	}
 }
 
-func TestGetCommitInfoFromStdout_InvalidCommitFormat(t *testing.T) {
+func TestParseCommits_InvalidCommitFormat(t *testing.T) {
 	input := "not a valid commit message"
 
-	_, err := getCommitInfoFromStdout(input)
+	_, err := parseCommits(input)
 	if err == nil {
 		t.Error("was expecting a error due to invalid commit format")
 		return
@@ -107,3 +186,36 @@ func assertCommitEqual(t *testing.T, actual, expected *Commit) {
 		t.Errorf("Message mismatch:\ngot:\n%q\nwant:\n%q", actual.Message, expected.Message)
 	}
 }
+
+func TestParseTrailers(t *testing.T) {
+	tt := []struct {
+		message string
+		want    []Trailer
+	}{
+		{message: "feat: login", want: nil},
+		{message: "Ult-Bump: build", want: nil},
+		{message: "feat: login\n\nadds sso", want: nil},
+		{
+			message: "feat: login\n\nadds sso\n\nUlt-Bump: minor\nSigned-off-by: John Doe <john@example.com>\n",
+			want:    []Trailer{{Key: "Ult-Bump", Value: "minor"}, {Key: "Signed-off-by", Value: "John Doe <john@example.com>"}},
+		},
+		{
+			message: "fix: crash\n\nRefs: APP-1,\n  APP-2",
+			want:    []Trailer{{Key: "Refs", Value: "APP-1, APP-2"}},
+		},
+		{message: "fix: crash\n\nRefs: APP-1\nnot a trailer", want: nil},
+	}
+
+	for _, tc := range tt {
+		got := ParseTrailers(tc.message)
+		if len(got) != len(tc.want) {
+			t.Errorf("ParseTrailers(%q) = %v, want %v", tc.message, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("ParseTrailers(%q) = %v, want %v", tc.message, got, tc.want)
+			}
+		}
+	}
+}
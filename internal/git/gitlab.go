@@ -0,0 +1,483 @@
+package git
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"ulist.app/ult/internal/assignee"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/paginate"
+)
+
+// Gitlab is the repository of a GitLab project accessed through the API, for
+// jobs running without a checkout.
+type Gitlab struct {
+	client    *gitlab.Client
+	projectID string
+	// branch is the current branch, GitLab has no working tree
+	branch string
+	// lastCommitIDs holds the last commit of the files read, by ref and path,
+	// writing a file back fails with ErrConflict when it changed since
+	lastCommitIDs map[string]string
+}
+
+// ErrConflict is returned when a file was changed on the branch after it was
+// read.
+var ErrConflict = errors.New("file was changed on the remote since it was read")
+
+// NewGitlab returns the repository of the project, branch is the branch
+// being worked on (e.g. CI_COMMIT_BRANCH).
+func NewGitlab(client *gitlab.Client, projectID, branch string) *Gitlab {
+	return &Gitlab{client: client, projectID: projectID, branch: branch, lastCommitIDs: map[string]string{}}
+}
+
+func (g *Gitlab) Backend() string {
+	return BackendGitlab
+}
+
+// CommitInfo returns the head of the current branch when ref is empty.
+func (g *Gitlab) CommitInfo(ref string) (*Commit, error) {
+	if len(ref) == 0 {
+		branch, err := g.CurrentBranch()
+		if err != nil {
+			return nil, err
+		}
+		ref = branch
+	}
+
+	commit, _, err := g.client.Commits.GetCommit(g.projectID, ref, &gitlab.GetCommitOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("fetching commit %s from gitlab project (%s): %w", ref, g.projectID, err)
+	}
+	return commitFromGitlab(commit), nil
+}
+
+func (g *Gitlab) TagsForCommit(hash, pattern string) ([]string, error) {
+	id, err := g.commitID(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for tag, err := range g.tags(pattern) {
+		if err != nil {
+			return nil, err
+		}
+		if tag.Commit != nil && tag.Commit.ID == id {
+			names = append(names, tag.Name)
+		}
+	}
+	return names, nil
+}
+
+// FindTag stops listing the tags once the tag is found.
+func (g *Gitlab) FindTag(hash, pattern string, accept func(tag string) bool) (string, error) {
+	id, err := g.commitID(hash)
+	if err != nil {
+		return "", err
+	}
+
+	for tag, err := range g.tags(pattern) {
+		if err != nil {
+			return "", err
+		}
+		if tag.Commit != nil && tag.Commit.ID == id && accept(tag.Name) {
+			return tag.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// commitID resolves a possibly abbreviated hash to the full ID of the
+// commit, so the tags are compared by equality.
+func (g *Gitlab) commitID(hash string) (string, error) {
+	if len(hash) == 0 {
+		return "", errors.New("the commit hash must not be empty")
+	}
+
+	commit, _, err := g.client.Commits.GetCommit(g.projectID, hash, &gitlab.GetCommitOptions{})
+	if err != nil {
+		return "", fmt.Errorf("fetching commit %s from gitlab project (%s): %w", hash, g.projectID, err)
+	}
+	return commit.ID, nil
+}
+
+func (g *Gitlab) ListTags(pattern string) ([]string, error) {
+	names := []string{}
+	for tag, err := range g.tags(pattern) {
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, tag.Name)
+	}
+	return names, nil
+}
+
+func (g *Gitlab) ListTagInfo(pattern string) ([]TagInfo, error) {
+	infos := []TagInfo{}
+	for tag, err := range g.tags(pattern) {
+		if err != nil {
+			return nil, err
+		}
+		// the API does not return the date of annotated tags
+		info := TagInfo{Name: tag.Name}
+		if tag.Commit != nil {
+			info.Commit = tag.Commit.ID
+			if tag.Commit.CommittedDate != nil {
+				info.Date = *tag.Commit.CommittedDate
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// tags iterates over the tags matching the glob pattern, the server only
+// returns the tags starting with its literal prefix.
+func (g *Gitlab) tags(pattern string) iter.Seq2[*gitlab.Tag, error] {
+	opt := gitlab.ListTagsOptions{}
+	if prefix := searchPrefix(pattern); len(prefix) > 0 {
+		opt.Search = gitlab.Ptr("^" + prefix)
+	}
+
+	pages := paginate.All(func(page gitlab.ListOptions) ([]*gitlab.Tag, *gitlab.Response, error) {
+		opt.ListOptions = page
+		return g.client.Tags.ListTags(g.projectID, &opt)
+	})
+	return func(yield func(*gitlab.Tag, error) bool) {
+		for tag, err := range pages {
+			if err != nil {
+				yield(nil, fmt.Errorf("fetching tag list from gitlab: %w", err))
+				return
+			}
+			if matchTag(tag.Name, pattern) && !yield(tag, nil) {
+				return
+			}
+		}
+	}
+}
+
+// DeleteTag deletes the tag from the project.
+func (g *Gitlab) DeleteTag(name string) error {
+	if dryrun.Enabled() {
+		dryrun.Printf("delete tag %s in project %s", name, g.projectID)
+		return nil
+	}
+
+	if _, err := g.client.Tags.DeleteTag(g.projectID, name); err != nil {
+		return fmt.Errorf("deleting tag %q: %w", name, err)
+	}
+	return nil
+}
+
+func (g *Gitlab) CompareRange(from, to string) ([]Commit, error) {
+	compare, _, err := g.client.Repositories.Compare(g.projectID, &gitlab.CompareOptions{
+		From: gitlab.Ptr(from),
+		To:   gitlab.Ptr(to),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching commit diff from gitlab branches: %v", err)
+	}
+
+	commits := make([]Commit, 0, len(compare.Commits))
+	for _, commit := range compare.Commits {
+		commits = append(commits, *commitFromGitlab(commit))
+	}
+	// the API lists the oldest commit first
+	slices.Reverse(commits)
+	return commits, nil
+}
+
+func (g *Gitlab) CurrentBranch() (string, error) {
+	if len(g.branch) == 0 {
+		return "", errors.New("the branch must be provided '--branch=main' when using the gitlab backend")
+	}
+	return g.branch, nil
+}
+
+// ReadFile reads the file at the head of the current branch when ref is
+// empty. The last commit of the file is remembered for WriteFiles.
+func (g *Gitlab) ReadFile(ref, path string) ([]byte, error) {
+	if len(ref) == 0 {
+		branch, err := g.CurrentBranch()
+		if err != nil {
+			return nil, err
+		}
+		ref = branch
+	}
+
+	file, err := g.getFile(ref, path)
+	if err != nil {
+		return nil, err
+	}
+	g.lastCommitIDs[ref+":"+path] = file.LastCommitID
+	if file.Encoding != "base64" {
+		return []byte(file.Content), nil
+	}
+	content, err := base64.StdEncoding.DecodeString(file.Content)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return content, nil
+}
+
+func (g *Gitlab) getFile(ref, path string) (*gitlab.File, error) {
+	if len(ref) == 0 {
+		branch, err := g.CurrentBranch()
+		if err != nil {
+			return nil, err
+		}
+		ref = branch
+	}
+
+	file, _, err := g.client.RepositoryFiles.GetFile(g.projectID, path, &gitlab.GetFileOptions{Ref: gitlab.Ptr(ref)})
+	if errors.Is(err, gitlab.ErrNotFound) {
+		return nil, fmt.Errorf("reading %s at %s: %w", path, ref, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s at %s: %w", path, ref, err)
+	}
+	return file, nil
+}
+
+// WriteFile creates or updates the file on the branch. Updates carry the
+// last commit of the file, so a concurrent change fails instead of being
+// overwritten.
+func (g *Gitlab) WriteFile(branch, path string, content []byte, message string) (*Commit, error) {
+	return g.WriteFiles(branch, message, map[string][]byte{path: content})
+}
+
+// WriteFiles creates or updates the files on the branch in a single commit.
+// Every update carries the last commit of its own file, as it was read or
+// else as it is on the branch, so a concurrent change fails with
+// ErrConflict instead of being overwritten.
+func (g *Gitlab) WriteFiles(branch, message string, files map[string][]byte) (*Commit, error) {
+	if len(branch) == 0 {
+		var err error
+		if branch, err = g.CurrentBranch(); err != nil {
+			return nil, err
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	actions := make([]*gitlab.CommitActionOptions, 0, len(paths))
+	for _, path := range paths {
+		action := &gitlab.CommitActionOptions{
+			Action:   gitlab.Ptr(gitlab.FileCreate),
+			FilePath: gitlab.Ptr(path),
+			Content:  gitlab.Ptr(string(files[path])),
+		}
+		lastCommitID, err := g.lastCommitID(branch, path)
+		if err != nil {
+			return nil, err
+		}
+		if len(lastCommitID) > 0 {
+			action.Action = gitlab.Ptr(gitlab.FileUpdate)
+			action.LastCommitID = gitlab.Ptr(lastCommitID)
+		}
+		actions = append(actions, action)
+	}
+
+	if dryrun.Enabled() {
+		dryrun.Printf("commit %s to branch %s of project %s with message %q",
+			strings.Join(paths, ", "), branch, g.projectID, message)
+		return &Commit{Message: message}, nil
+	}
+
+	commit, _, err := g.client.Commits.CreateCommit(g.projectID, &gitlab.CreateCommitOptions{
+		AuthorName:    gitlab.Ptr(Name),
+		AuthorEmail:   gitlab.Ptr(Email),
+		CommitMessage: gitlab.Ptr(message),
+		Branch:        gitlab.Ptr(branch),
+		Actions:       actions,
+	})
+	if isConflict(err) {
+		return nil, fmt.Errorf("committing %s to %s: %w, run again to apply the change on top of the new content", strings.Join(paths, ", "), branch, ErrConflict)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to update %s in repo: %w", strings.Join(paths, ", "), err)
+	}
+	logger.Info("Committed files", "commit", commit.ID, "branch", branch, "paths", paths)
+	return commitFromGitlab(commit), nil
+}
+
+// lastCommitID returns the last commit of the file when it was read from the
+// branch, else its last commit on the branch. Empty when it does not exist.
+func (g *Gitlab) lastCommitID(branch, path string) (string, error) {
+	if id, ok := g.lastCommitIDs[branch+":"+path]; ok {
+		return id, nil
+	}
+
+	file, err := g.getFile(branch, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return file.LastCommitID, nil
+}
+
+// isConflict reports whether GitLab rejected the commit because a file
+// changed after its last_commit_id.
+func isConflict(err error) bool {
+	var errorResponse *gitlab.ErrorResponse
+	if !errors.As(err, &errorResponse) || errorResponse.Response == nil {
+		return false
+	}
+	return errorResponse.Response.StatusCode == http.StatusBadRequest &&
+		strings.Contains(errorResponse.Message, "changed since")
+}
+
+// CreateTag creates the tag, annotated when there is a message. An existing
+// tag is moved by deleting and creating it again. The API can not sign
+// tags, so it fails when signing is enabled.
+func (g *Gitlab) CreateTag(name, ref, message string, move bool) (*TagChange, error) {
+	if SigningEnabled() {
+		return nil, fmt.Errorf("creating tag %q: the GitLab API can not sign tags, use '--backend git'", name)
+	}
+
+	existing, err := g.getTag(name)
+	if err != nil {
+		return nil, err
+	}
+	previous, previousMessage := "", ""
+	if existing != nil && existing.Commit != nil {
+		previous, previousMessage = existing.Commit.ID, existing.Message
+	}
+	change := &TagChange{Tag: name, Previous: previous}
+	if len(previous) > 0 {
+		commit, _, err := g.client.Commits.GetCommit(g.projectID, ref, &gitlab.GetCommitOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("fetching commit %s from gitlab project (%s): %w", ref, g.projectID, err)
+		}
+		change.Commit = commit.ID
+
+		if change.Unchanged() {
+			logger.Info("Tag already points to the commit", "tag", name, "commit", change.Commit)
+			return change, nil
+		}
+		if !move {
+			return nil, tagExistsError(change)
+		}
+	}
+
+	if dryrun.Enabled() {
+		if change.Moved() {
+			dryrun.Printf("move tag %s from %s to ref %s in project %s", name, previous, ref, g.projectID)
+		}
+		dryrun.Printf("create tag %s at ref %s in project %s with message %q", name, ref, g.projectID, message)
+		return change, nil
+	}
+
+	if change.Moved() {
+		if _, err := g.client.Tags.DeleteTag(g.projectID, name); err != nil {
+			return nil, fmt.Errorf("deleting tag %q to move it: %w", name, err)
+		}
+	}
+
+	opt := &gitlab.CreateTagOptions{
+		TagName: gitlab.Ptr(name),
+		Ref:     gitlab.Ptr(ref),
+	}
+	if len(message) > 0 {
+		opt.Message = gitlab.Ptr(message)
+	}
+	tag, _, err := g.client.Tags.CreateTag(g.projectID, opt)
+	if err != nil {
+		if change.Moved() {
+			return nil, g.restoreTag(name, previous, previousMessage, err)
+		}
+		return nil, fmt.Errorf("creating tag %q: %w", name, err)
+	}
+	change.Commit = tag.Commit.ID
+
+	if change.Moved() {
+		auditTagMove(BackendGitlab, change)
+	}
+	return change, nil
+}
+
+// restoreTag creates the tag deleted to move it at its previous commit
+// again, with its message when it was annotated, after the create at the
+// new commit failed with cause.
+func (g *Gitlab) restoreTag(name, previous, message string, cause error) error {
+	opt := &gitlab.CreateTagOptions{
+		TagName: gitlab.Ptr(name),
+		Ref:     gitlab.Ptr(previous),
+	}
+	if len(message) > 0 {
+		opt.Message = gitlab.Ptr(message)
+	}
+	if _, _, err := g.client.Tags.CreateTag(g.projectID, opt); err != nil {
+		logger.Error("Failed to restore tag", "tag", name, "commit", previous, "error", err)
+		return fmt.Errorf("creating tag %q: %w (it was deleted from %s to move it, restoring it failed: %v)", name, cause, previous, err)
+	}
+	logger.Warn("Restored tag", "tag", name, "commit", previous)
+	return fmt.Errorf("creating tag %q: %w (it was restored at %s)", name, cause, previous)
+}
+
+func (g *Gitlab) TagTarget(name string) (string, error) {
+	tag, err := g.getTag(name)
+	if err != nil || tag == nil || tag.Commit == nil {
+		return "", err
+	}
+	return tag.Commit.ID, nil
+}
+
+// getTag returns the tag, nil when it does not exist.
+func (g *Gitlab) getTag(name string) (*gitlab.Tag, error) {
+	tag, _, err := g.client.Tags.GetTag(g.projectID, name)
+	if errors.Is(err, gitlab.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching tag %s from gitlab: %w", name, err)
+	}
+	return tag, nil
+}
+
+func (g *Gitlab) MergeRequestTitles(hash string) ([]string, error) {
+	mrs, err := paginate.Collect(func(page gitlab.ListOptions) ([]*gitlab.BasicMergeRequest, *gitlab.Response, error) {
+		return g.client.Commits.ListMergeRequestsByCommit(g.projectID, hash, paginate.WithPage(page))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching merge requests of commit %s: %w", hash, err)
+	}
+
+	titles := make([]string, 0, len(mrs))
+	for _, mr := range mrs {
+		titles = append(titles, mr.Title)
+	}
+	return titles, nil
+}
+
+func commitFromGitlab(commit *gitlab.Commit) *Commit {
+	c := &Commit{
+		Hash:      commit.ID,
+		Parents:   commit.ParentIDs,
+		Assignee:  assignee.Assignee{Name: commit.AuthorName, Email: commit.AuthorEmail},
+		Committer: assignee.Assignee{Name: commit.CommitterName, Email: commit.CommitterEmail},
+		WebURL:    commit.WebURL,
+	}
+	if commit.AuthoredDate != nil {
+		c.Date = *commit.AuthoredDate
+	}
+	if commit.CommittedDate != nil {
+		c.CommitDate = *commit.CommittedDate
+	}
+	c.setMessage(commit.Message)
+	return c
+}
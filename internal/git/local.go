@@ -0,0 +1,150 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"ulist.app/ult/internal/dryrun"
+)
+
+// Local is the repository of the working directory, accessed with the git
+// executable.
+type Local struct{}
+
+// NewLocal returns the repository of the working directory.
+func NewLocal() *Local {
+	return &Local{}
+}
+
+func (l *Local) Backend() string {
+	return BackendGit
+}
+
+func (l *Local) CommitInfo(ref string) (*Commit, error) {
+	if len(ref) == 0 {
+		return GetLatestCommitInfo()
+	}
+	return GetCommitInfo(ref)
+}
+
+func (l *Local) TagsForCommit(hash, pattern string) ([]string, error) {
+	args := []string{"tag", "--points-at", hash}
+	if len(pattern) > 0 {
+		args = append(args, "--list", pattern)
+	}
+
+	output, err := execCommand("git", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag from given commit hash (%s): %w", hash, err)
+	}
+	return splitLines(string(output)), nil
+}
+
+func (l *Local) FindTag(hash, pattern string, accept func(tag string) bool) (string, error) {
+	tags, err := l.TagsForCommit(hash, pattern)
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags {
+		if accept(tag) {
+			return tag, nil
+		}
+	}
+	return "", nil
+}
+
+func (l *Local) ListTags(pattern string) ([]string, error) {
+	return ListTags(pattern)
+}
+
+func (l *Local) CompareRange(from, to string) ([]Commit, error) {
+	return GetCommitRange(from, to)
+}
+
+func (l *Local) CurrentBranch() (string, error) {
+	return GetCurrentBranch()
+}
+
+// ReadFile reads the file from the working tree when ref is empty.
+func (l *Local) ReadFile(ref, path string) ([]byte, error) {
+	if len(ref) == 0 {
+		return os.ReadFile(path)
+	}
+
+	if _, err := execCommand("git", "cat-file", "-e", ref+":"+path); err != nil {
+		return nil, fmt.Errorf("reading %s at %s: %w", path, ref, os.ErrNotExist)
+	}
+	output, err := execCommand("git", "show", ref+":"+path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s at %s: %w", path, ref, err)
+	}
+	return output, nil
+}
+
+// WriteFile writes the file to the working tree and commits it, the branch
+// must be the current one.
+func (l *Local) WriteFile(branch, path string, content []byte, message string) (*Commit, error) {
+	return l.WriteFiles(branch, message, map[string][]byte{path: content})
+}
+
+// WriteFiles writes the files to the working tree and commits them, the
+// branch must be the current one.
+func (l *Local) WriteFiles(branch, message string, files map[string][]byte) (*Commit, error) {
+	current, err := GetCurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+	if len(branch) > 0 && branch != current {
+		return nil, fmt.Errorf("can not commit to %s, the current branch is %s", branch, current)
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		if dryrun.Enabled() {
+			dryrun.Printf("write %s (%d bytes)", path, len(files[path]))
+		} else if err := os.WriteFile(path, files[path], 0o644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", path, err)
+		}
+	}
+
+	if err := CommitFiles(message, paths...); err != nil {
+		return nil, err
+	}
+	if dryrun.Enabled() {
+		return &Commit{Message: message}, nil
+	}
+	return GetLatestCommitInfo()
+}
+
+func (l *Local) CreateTag(name, ref, message string, move bool) (*TagChange, error) {
+	return CreateTag(name, ref, message, move)
+}
+
+func (l *Local) TagTarget(name string) (string, error) {
+	return TagTarget(name)
+}
+
+func (l *Local) ListTagInfo(pattern string) ([]TagInfo, error) {
+	return ListTagInfo(pattern)
+}
+
+func (l *Local) DeleteTag(name string) error {
+	return DeleteTag(name)
+}
+
+func splitLines(output string) []string {
+	lines := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
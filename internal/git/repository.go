@@ -0,0 +1,99 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Repository backends, selected with the global --backend setting.
+const (
+	BackendGit    = "git"
+	BackendGitlab = "gitlab"
+)
+
+// Repository is the repository of the project, either the local clone
+// (Local) or the GitLab project through the API (Gitlab), so every command
+// supports both modes the same way.
+type Repository interface {
+	// Backend returns the name of the backend, BackendGit or BackendGitlab.
+	Backend() string
+	// CommitInfo returns the commit ref points to, an empty ref is the
+	// latest commit of the current branch.
+	CommitInfo(ref string) (*Commit, error)
+	// TagsForCommit returns the tags pointing at the commit, optionally
+	// limited to the glob pattern, e.g. "QA-v*".
+	TagsForCommit(hash, pattern string) ([]string, error)
+	// FindTag returns the first tag pointing at the commit that matches the
+	// glob pattern and is accepted, an empty string when there is none.
+	FindTag(hash, pattern string, accept func(tag string) bool) (string, error)
+	// ListTags returns the tags matching the glob pattern.
+	ListTags(pattern string) ([]string, error)
+	// CompareRange returns the commits reachable from to but not from from
+	// (git log from..to), newest first.
+	CompareRange(from, to string) ([]Commit, error)
+	// CurrentBranch returns the branch being worked on.
+	CurrentBranch() (string, error)
+	// ReadFile returns the content of the file at ref, an error wrapping
+	// os.ErrNotExist when it does not exist.
+	ReadFile(ref, path string) ([]byte, error)
+	// WriteFile commits the content of the file to the branch.
+	WriteFile(branch, path string, content []byte, message string) (*Commit, error)
+	// WriteFiles commits the content of the files to the branch in a single
+	// commit, an empty branch is the current one.
+	WriteFiles(branch, message string, files map[string][]byte) (*Commit, error)
+	// CreateTag creates the tag at ref, annotated when the message is not
+	// empty. An existing tag pointing to another commit is only moved when
+	// move is set, otherwise the error wraps ErrTagExists.
+	CreateTag(name, ref, message string, move bool) (*TagChange, error)
+	// TagTarget returns the commit the tag points to, an empty string when
+	// the tag does not exist.
+	TagTarget(name string) (string, error)
+	// ListTagInfo returns the tags matching the glob pattern with their
+	// commit and date.
+	ListTagInfo(pattern string) ([]TagInfo, error)
+	// DeleteTag deletes the tag.
+	DeleteTag(name string) error
+}
+
+// MergeRequests is implemented by the repositories that know the merge
+// requests of a commit, only Gitlab as a clone has no merge requests.
+type MergeRequests interface {
+	// MergeRequestTitles returns the titles of the merge requests
+	// containing the commit.
+	MergeRequestTitles(hash string) ([]string, error)
+}
+
+var (
+	_ Repository    = (*Local)(nil)
+	_ Repository    = (*Gitlab)(nil)
+	_ MergeRequests = (*Gitlab)(nil)
+)
+
+// ValidateBackend returns an error for unknown backend names.
+func ValidateBackend(backend string) error {
+	switch backend {
+	case BackendGit, BackendGitlab:
+		return nil
+	}
+	return fmt.Errorf("unknown backend '%s' (valid backends: %s, %s)", backend, BackendGit, BackendGitlab)
+}
+
+// matchTag reports whether the tag matches the glob pattern, every tag
+// matches an empty pattern.
+func matchTag(tag, pattern string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	ok, _ := path.Match(pattern, tag)
+	return ok
+}
+
+// searchPrefix returns the literal prefix of the glob pattern, used to
+// narrow down tag searches on the server.
+func searchPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
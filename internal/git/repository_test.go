@@ -0,0 +1,359 @@
+package git
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestSearchPrefix(t *testing.T) {
+	tt := map[string]string{
+		"":           "",
+		"QA-v*":      "QA-v",
+		"PROD-v202?": "PROD-v202",
+		"v[0-9]*":    "v",
+		"release":    "release",
+	}
+
+	for pattern, want := range tt {
+		if got := searchPrefix(pattern); got != want {
+			t.Errorf("searchPrefix(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestGitlabTagsForCommit(t *testing.T) {
+	var search string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/1/repository/tags", func(w http.ResponseWriter, r *http.Request) {
+		search = r.URL.Query().Get("search")
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"name": "QA-v2025.200.01+02", "commit": map[string]string{"id": "abc123"}},
+			{"name": "QA-v2025.200.01+01", "commit": map[string]string{"id": "def456"}},
+			{"name": "QA-vnext", "commit": map[string]string{"id": "abc123"}},
+			{"name": "QA-v2025.200.01+03", "commit": map[string]string{"id": "abc1234"}},
+		})
+	})
+	mux.HandleFunc("GET /api/v4/projects/1/repository/commits/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"id": "abc123"})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(srv.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewGitlab(client, "1", "main")
+
+	got, err := repo.TagsForCommit("abc", "QA-v2*")
+	if err != nil {
+		t.Fatalf("TagsForCommit() error = %v", err)
+	}
+	if want := []string{"QA-v2025.200.01+02"}; !slices.Equal(got, want) {
+		t.Errorf("TagsForCommit() = %v, want %v", got, want)
+	}
+	if search != "^QA-v2" {
+		t.Errorf("search = %q, want ^QA-v2", search)
+	}
+
+	if _, err := repo.TagsForCommit("", "QA-v*"); err == nil {
+		t.Error("TagsForCommit() of an empty hash error = nil, want error")
+	}
+}
+
+func TestGitlabCreateTagExisting(t *testing.T) {
+	var deleted, created bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/1/repository/tags/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("tag") != "v1" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "404 Tag Not Found"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"name": "v1", "commit": map[string]string{"id": "old123"}})
+	})
+	mux.HandleFunc("GET /api/v4/projects/1/repository/commits/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		id := map[string]string{"main": "new456", "old": "old123"}[r.PathValue("sha")]
+		json.NewEncoder(w).Encode(map[string]string{"id": id})
+	})
+	mux.HandleFunc("DELETE /api/v4/projects/1/repository/tags/v1", func(w http.ResponseWriter, r *http.Request) {
+		deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/v4/projects/1/repository/tags", func(w http.ResponseWriter, r *http.Request) {
+		created = true
+		json.NewEncoder(w).Encode(map[string]any{"name": r.URL.Query().Get("tag_name"), "commit": map[string]string{"id": "new456"}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(srv.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewGitlab(client, "1", "main")
+
+	change, err := repo.CreateTag("v1", "old", "", false)
+	if err != nil || !change.Unchanged() {
+		t.Errorf("CreateTag() at the same commit = %+v, %v, want unchanged", change, err)
+	}
+
+	if _, err := repo.CreateTag("v1", "main", "", false); !errors.Is(err, ErrTagExists) {
+		t.Errorf("CreateTag() at another commit error = %v, want ErrTagExists", err)
+	}
+	if deleted || created {
+		t.Fatal("CreateTag() changed the tag without move")
+	}
+
+	change, err = repo.CreateTag("v1", "main", "", true)
+	if err != nil {
+		t.Fatalf("CreateTag() with move error = %v", err)
+	}
+	if !change.Moved() || change.Previous != "old123" || change.Commit != "new456" || !deleted || !created {
+		t.Errorf("CreateTag() with move = %+v (deleted %t, created %t)", change, deleted, created)
+	}
+}
+
+func TestGitlabFindTagPages(t *testing.T) {
+	requested := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/1/repository/tags", func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		requested = append(requested, page)
+		if !strings.HasPrefix(r.URL.Query().Get("search"), "^QA-v") || r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+
+		tags := map[string][]map[string]any{
+			"1": {{"name": "QA-v2025.200.01+03", "commit": map[string]string{"id": "aaa111"}}},
+			"2": {
+				{"name": "QA-vnext", "commit": map[string]string{"id": "abc123"}},
+				{"name": "QA-v2025.200.01+02", "commit": map[string]string{"id": "abc123"}},
+			},
+			"3": {{"name": "QA-v2025.200.01+01", "commit": map[string]string{"id": "abc123"}}},
+		}[page]
+		if page != "3" {
+			next, _ := strconv.Atoi(page)
+			w.Header().Set("X-Next-Page", strconv.Itoa(next+1))
+		}
+		json.NewEncoder(w).Encode(tags)
+	})
+	mux.HandleFunc("GET /api/v4/projects/1/repository/commits/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"id": "abc123"})
+	})
+	mux.HandleFunc("GET /api/v4/projects/1/repository/commits/abc123/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("merge requests query = %s", r.URL.RawQuery)
+		}
+		if page == "1" {
+			w.Header().Set("X-Next-Page", "2")
+		}
+		json.NewEncoder(w).Encode([]map[string]string{{"title": "ULT-" + page}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(srv.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewGitlab(client, "1", "main")
+
+	tag, err := repo.FindTag("abc", "QA-v*", func(tag string) bool { return tag != "QA-vnext" })
+	if err != nil || tag != "QA-v2025.200.01+02" {
+		t.Errorf("FindTag() = %q, %v, want QA-v2025.200.01+02", tag, err)
+	}
+	if want := []string{"1", "2"}; !slices.Equal(requested, want) {
+		t.Errorf("requested pages = %v, want %v", requested, want)
+	}
+
+	requested = nil
+	tags, err := repo.TagsForCommit("abc", "QA-v2*")
+	if want := []string{"QA-v2025.200.01+02", "QA-v2025.200.01+01"}; err != nil || !slices.Equal(tags, want) {
+		t.Errorf("TagsForCommit() = %v, %v, want %v", tags, err, want)
+	}
+	if want := []string{"1", "2", "3"}; !slices.Equal(requested, want) {
+		t.Errorf("requested pages = %v, want %v", requested, want)
+	}
+
+	titles, err := repo.MergeRequestTitles("abc123")
+	if want := []string{"ULT-1", "ULT-2"}; err != nil || !slices.Equal(titles, want) {
+		t.Errorf("MergeRequestTitles() = %v, %v, want %v", titles, err, want)
+	}
+}
+
+func TestGitlabWriteFiles(t *testing.T) {
+	lastCommits := map[string]string{"pubspec.yaml": "aaa111", "android/app/build.gradle": "bbb222"}
+	var commit gitlab.CreateCommitOptions
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/1/repository/files/{path}", func(w http.ResponseWriter, r *http.Request) {
+		path := r.PathValue("path")
+		lastCommit, ok := lastCommits[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "404 File Not Found"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"file_path": path, "encoding": "base64", "last_commit_id": lastCommit})
+	})
+	mux.HandleFunc("POST /api/v4/projects/1/repository/commits", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&commit); err != nil {
+			t.Errorf("decoding commit: %v", err)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id": "ccc333"})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(srv.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewGitlab(client, "1", "main")
+
+	got, err := repo.WriteFiles("main", "bump version [skip ci]", map[string][]byte{
+		"pubspec.yaml":             []byte("version: 2025.200.01+05\n"),
+		"android/app/build.gradle": []byte("versionCode 5\n"),
+		"lib/version.g.dart":       []byte("const v = '2025.200.01+05';\n"),
+	})
+	if err != nil || got.Hash != "ccc333" {
+		t.Fatalf("WriteFiles() = %+v, %v, want commit ccc333", got, err)
+	}
+
+	want := map[string]string{"android/app/build.gradle": "bbb222", "lib/version.g.dart": "", "pubspec.yaml": "aaa111"}
+	if len(commit.Actions) != len(want) {
+		t.Fatalf("got %d actions, want %d", len(commit.Actions), len(want))
+	}
+	for _, action := range commit.Actions {
+		lastCommit := ""
+		if action.LastCommitID != nil {
+			lastCommit = *action.LastCommitID
+		}
+		wantAction := gitlab.FileUpdate
+		if len(want[*action.FilePath]) == 0 {
+			wantAction = gitlab.FileCreate
+		}
+		if *action.Action != wantAction || lastCommit != want[*action.FilePath] {
+			t.Errorf("%s: action = %s with last_commit_id %q, want %s with %q", *action.FilePath, *action.Action, lastCommit, wantAction, want[*action.FilePath])
+		}
+	}
+}
+
+func TestGitlabReadFile(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/1/repository/files/{path}", func(w http.ResponseWriter, r *http.Request) {
+		files := map[string]map[string]string{
+			"base64.yaml": {"encoding": "base64", "content": base64.StdEncoding.EncodeToString([]byte("version: 1\n"))},
+			"text.yaml":   {"encoding": "text", "content": "version: 1\n"},
+		}
+		json.NewEncoder(w).Encode(files[r.PathValue("path")])
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(srv.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewGitlab(client, "1", "main")
+
+	for _, path := range []string{"base64.yaml", "text.yaml"} {
+		got, err := repo.ReadFile("", path)
+		if err != nil || string(got) != "version: 1\n" {
+			t.Errorf("ReadFile(%s) = %q, %v, want %q", path, got, err, "version: 1\n")
+		}
+	}
+}
+
+func TestGitlabWriteFilesAfterRead(t *testing.T) {
+	reads := 0
+	var commit gitlab.CreateCommitOptions
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/1/repository/files/{path}", func(w http.ResponseWriter, r *http.Request) {
+		// every request sees a newer commit of the file
+		reads++
+		json.NewEncoder(w).Encode(map[string]string{"encoding": "text", "content": "version: 1\n", "last_commit_id": "commit" + strconv.Itoa(reads)})
+	})
+	mux.HandleFunc("POST /api/v4/projects/1/repository/commits", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&commit)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "You are attempting to update a file that has changed since you started editing it."})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(srv.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewGitlab(client, "1", "main")
+
+	if _, err := repo.ReadFile("", "pubspec.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = repo.WriteFile("", "pubspec.yaml", []byte("version: 2\n"), "bump version [skip ci]")
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("WriteFile() error = %v, want ErrConflict", err)
+	}
+	if reads != 1 || len(commit.Actions) != 1 || *commit.Actions[0].LastCommitID != "commit1" {
+		t.Errorf("WriteFile() did not commit on top of the read file (%d reads)", reads)
+	}
+}
+
+func TestGitlabCreateTagMoveRestores(t *testing.T) {
+	created := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/1/repository/tags/v1", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"name": "v1", "message": "Release v1", "commit": map[string]string{"id": "old123"}})
+	})
+	mux.HandleFunc("GET /api/v4/projects/1/repository/commits/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"id": "new456"})
+	})
+	mux.HandleFunc("DELETE /api/v4/projects/1/repository/tags/v1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/v4/projects/1/repository/tags", func(w http.ResponseWriter, r *http.Request) {
+		var opt gitlab.CreateTagOptions
+		json.NewDecoder(r.Body).Decode(&opt)
+		ref, message := r.URL.Query().Get("ref"), r.URL.Query().Get("message")
+		if opt.Ref != nil {
+			ref = *opt.Ref
+		}
+		if opt.Message != nil {
+			message = *opt.Message
+		}
+		created = append(created, ref+" "+message)
+		if ref == "main" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Target main is invalid"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"name": "v1", "commit": map[string]string{"id": ref}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(srv.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewGitlab(client, "1", "main")
+
+	_, err = repo.CreateTag("v1", "main", "Release v1 again", true)
+	if err == nil || !strings.Contains(err.Error(), "restored at old123") {
+		t.Errorf("CreateTag() error = %v, want the tag restored", err)
+	}
+	if want := []string{"main Release v1 again", "old123 Release v1"}; !slices.Equal(created, want) {
+		t.Errorf("created tags = %q, want %q", created, want)
+	}
+}
@@ -0,0 +1,195 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Signing formats, selected with the global --sign setting.
+const (
+	SignGPG = "gpg"
+	SignSSH = "ssh"
+)
+
+// Signing configures the signature of the commits and tags created with
+// git. Key is the key id (gpg) or the public key file (ssh), when empty the
+// user.signingkey of the git configuration is used.
+type Signing struct {
+	Format string
+	Key    string
+}
+
+var signing Signing
+
+// SetSigning signs the commits and tags created from now on, a zero Signing
+// creates unsigned ones.
+func SetSigning(s Signing) error {
+	switch s.Format {
+	case "", SignGPG, SignSSH:
+	default:
+		return fmt.Errorf("unknown signing format '%s' (valid formats: %s, %s)", s.Format, SignGPG, SignSSH)
+	}
+	if len(s.Format) == 0 && len(s.Key) > 0 {
+		return errors.New("a signing key requires a signing format '--sign=gpg' or '--sign=ssh'")
+	}
+	signing = s
+	return nil
+}
+
+// SigningEnabled reports whether commits and tags are signed.
+func SigningEnabled() bool {
+	return len(signing.Format) > 0
+}
+
+// configArgs returns the git options selecting the signature format and
+// key, they go before the git subcommand.
+func (s Signing) configArgs() []string {
+	if len(s.Format) == 0 {
+		return nil
+	}
+
+	format := "openpgp"
+	if s.Format == SignSSH {
+		format = "ssh"
+	}
+	args := []string{"-c", "gpg.format=" + format}
+	if len(s.Key) > 0 {
+		args = append(args, "-c", "user.signingkey="+s.Key)
+	}
+	return args
+}
+
+// tagArgs returns the git arguments creating the tag, annotated when there
+// is a message and signed when signing is enabled. A signed tag is always
+// annotated, by default with the tag name as message.
+func tagArgs(name, message string) []string {
+	args := append(signing.configArgs(), "tag")
+	if SigningEnabled() {
+		args = append(args, "--sign")
+		if len(message) == 0 {
+			message = name
+		}
+	}
+	if len(message) > 0 {
+		args = append(args, "--annotate", "--message", message)
+	}
+	return append(args, name)
+}
+
+// commitArgs returns the git arguments of a commit, signed when signing is
+// enabled.
+func commitArgs(args ...string) []string {
+	commit := append(signing.configArgs(), "commit")
+	if SigningEnabled() {
+		commit = append(commit, "--gpg-sign")
+	}
+	return append(commit, args...)
+}
+
+// TagVerification is the signature of a tag.
+type TagVerification struct {
+	Tag       string          `json:"tag" yaml:"tag"`
+	Annotated bool            `json:"annotated" yaml:"annotated"`
+	Signature SignatureStatus `json:"signature" yaml:"signature"`
+	Signer    string          `json:"signer,omitempty" yaml:"signer,omitempty"`
+}
+
+// Verified reports whether the tag is signed with a good, trusted signature.
+func (t TagVerification) Verified() bool {
+	return t.Signature == SignatureGood
+}
+
+// VerifyTag checks the signature of the tag with git verify-tag. Lightweight
+// and unsigned tags have SignatureNone.
+func VerifyTag(name string) (*TagVerification, error) {
+	result := &TagVerification{Tag: name, Signature: SignatureNone}
+
+	kind, err := execCommand("git", "cat-file", "-t", "refs/tags/"+name)
+	if err != nil {
+		return nil, fmt.Errorf("tag %s not found: %w", name, err)
+	}
+	if strings.TrimSpace(string(kind)) != "tag" {
+		return result, nil
+	}
+	result.Annotated = true
+
+	object, err := execCommand("git", "cat-file", "tag", "refs/tags/"+name)
+	if err != nil {
+		return nil, fmt.Errorf("reading tag %s: %w", name, err)
+	}
+	if !bytes.Contains(object, []byte("-----BEGIN ")) {
+		return result, nil
+	}
+
+	logger.Debug("Executing command", "cmd", "git", "args", []string{"verify-tag", "--raw", name})
+	cmd := exec.Command("git", "verify-tag", "--raw", "refs/tags/"+name)
+	output, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("verifying tag %s: %w", name, err)
+	}
+
+	result.Signature, result.Signer = parseVerifyOutput(string(output), err == nil)
+	return result, nil
+}
+
+// gpgStatuses maps the status lines of gpg --status-fd to the status, the
+// first one found wins.
+var gpgStatuses = []struct {
+	keyword string
+	status  SignatureStatus
+}{
+	{"BADSIG", SignatureBad},
+	{"REVKEYSIG", SignatureRevokedKey},
+	{"EXPKEYSIG", SignatureExpiredKey},
+	{"EXPSIG", SignatureExpired},
+	{"ERRSIG", SignatureUnverifiable},
+	{"GOODSIG", SignatureGood},
+}
+
+// parseVerifyOutput reads the raw output of git verify-tag, gpg status
+// lines or the messages of ssh-keygen -Y verify.
+func parseVerifyOutput(output string, ok bool) (SignatureStatus, string) {
+	if strings.Contains(output, "[GNUPG:] ") {
+		status, signer := SignatureUnverifiable, ""
+		for _, s := range gpgStatuses {
+			_, rest, found := strings.Cut(output, "[GNUPG:] "+s.keyword+" ")
+			if !found {
+				continue
+			}
+			status = s.status
+			line, _, _ := strings.Cut(rest, "\n")
+			// the key id is followed by the user id, only the key id is known
+			// when the key is missing
+			keyID, user, _ := strings.Cut(line, " ")
+			signer = user
+			if status == SignatureUnverifiable {
+				signer = keyID
+			}
+			break
+		}
+		if status == SignatureGood && (strings.Contains(output, "[GNUPG:] TRUST_UNDEFINED") || strings.Contains(output, "[GNUPG:] TRUST_NEVER")) {
+			status = SignatureUntrusted
+		}
+		return status, signer
+	}
+
+	// ssh: Good "git" signature for ci@example.com with ED25519 key SHA256:...
+	if _, rest, found := strings.Cut(output, `Good "git" signature for `); found && ok {
+		signer, _, _ := strings.Cut(rest, " with ")
+		return SignatureGood, signer
+	}
+	if strings.Contains(output, "No principal matched") {
+		return SignatureUntrusted, ""
+	}
+	if strings.Contains(output, "allowedSignersFile") {
+		return SignatureUnverifiable, ""
+	}
+	if ok {
+		return SignatureGood, ""
+	}
+	return SignatureBad, ""
+}
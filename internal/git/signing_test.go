@@ -0,0 +1,114 @@
+package git
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTagArgs(t *testing.T) {
+	tt := []struct {
+		name    string
+		signing Signing
+		message string
+		want    []string
+	}{
+		{name: "lightweight", want: []string{"tag", "v1"}},
+		{name: "annotated", message: "Release v1", want: []string{"tag", "--annotate", "--message", "Release v1", "v1"}},
+		{
+			name:    "gpg",
+			signing: Signing{Format: SignGPG, Key: "ABCD1234"},
+			want:    []string{"-c", "gpg.format=openpgp", "-c", "user.signingkey=ABCD1234", "tag", "--sign", "--annotate", "--message", "v1", "v1"},
+		},
+		{
+			name:    "ssh",
+			signing: Signing{Format: SignSSH},
+			message: "Release v1",
+			want:    []string{"-c", "gpg.format=ssh", "tag", "--sign", "--annotate", "--message", "Release v1", "v1"},
+		},
+	}
+
+	defer SetSigning(Signing{})
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := SetSigning(tc.signing); err != nil {
+				t.Fatalf("SetSigning() error = %v", err)
+			}
+			if got := tagArgs("v1", tc.message); !slices.Equal(got, tc.want) {
+				t.Errorf("tagArgs() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetSigningInvalid(t *testing.T) {
+	defer SetSigning(Signing{})
+	if err := SetSigning(Signing{Format: "x509"}); err == nil {
+		t.Error("SetSigning() with unknown format returned no error")
+	}
+	if err := SetSigning(Signing{Key: "ABCD1234"}); err == nil {
+		t.Error("SetSigning() with a key but no format returned no error")
+	}
+}
+
+func TestParseVerifyOutput(t *testing.T) {
+	tt := []struct {
+		name       string
+		output     string
+		ok         bool
+		wantStatus SignatureStatus
+		wantSigner string
+	}{
+		{
+			name:       "gpg good",
+			output:     "[GNUPG:] NEWSIG\n[GNUPG:] GOODSIG 1234ABCD CI <ci@example.com>\n[GNUPG:] VALIDSIG F00\n[GNUPG:] TRUST_ULTIMATE 0 pgp\n",
+			ok:         true,
+			wantStatus: SignatureGood,
+			wantSigner: "CI <ci@example.com>",
+		},
+		{
+			name:       "gpg untrusted",
+			output:     "[GNUPG:] GOODSIG 1234ABCD CI <ci@example.com>\n[GNUPG:] TRUST_UNDEFINED 0 pgp\n",
+			ok:         true,
+			wantStatus: SignatureUntrusted,
+			wantSigner: "CI <ci@example.com>",
+		},
+		{
+			name:       "gpg bad",
+			output:     "[GNUPG:] BADSIG 1234ABCD CI <ci@example.com>\n",
+			wantStatus: SignatureBad,
+			wantSigner: "CI <ci@example.com>",
+		},
+		{
+			name:       "gpg missing key",
+			output:     "[GNUPG:] ERRSIG 1234ABCD 1 10 00 1700000000 9 F00\n[GNUPG:] NO_PUBKEY 1234ABCD\n",
+			wantStatus: SignatureUnverifiable,
+			wantSigner: "1234ABCD",
+		},
+		{
+			name:       "ssh good",
+			output:     `Good "git" signature for ci@example.com with ED25519 key SHA256:abc`,
+			ok:         true,
+			wantStatus: SignatureGood,
+			wantSigner: "ci@example.com",
+		},
+		{
+			name:       "ssh no allowed signers",
+			output:     "error: gpg.ssh.allowedSignersFile needs to be configured and exist for ssh signature verification",
+			wantStatus: SignatureUnverifiable,
+		},
+		{
+			name:       "ssh bad",
+			output:     "Signature verification failed: incorrect signature",
+			wantStatus: SignatureBad,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			status, signer := parseVerifyOutput(tc.output, tc.ok)
+			if status != tc.wantStatus || signer != tc.wantSigner {
+				t.Errorf("parseVerifyOutput() = %q, %q, want %q, %q", status, signer, tc.wantStatus, tc.wantSigner)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/logging"
+)
+
+// ErrTagExists is returned when a tag already points to another commit and
+// moving it was not allowed.
+var ErrTagExists = errors.New("tag already exists")
+
+var (
+	// auditLogger records the tags that were moved, a moved release tag
+	// changes what was released.
+	auditLogger = logging.New("audit")
+)
+
+// TagChange is the result of creating a tag. Previous is the commit the tag
+// pointed to before, empty for a new tag and equal to Commit when the tag
+// was already in place.
+type TagChange struct {
+	Tag      string `json:"tag" yaml:"tag"`
+	Commit   string `json:"commit" yaml:"commit"`
+	Previous string `json:"previous,omitempty" yaml:"previous,omitempty"`
+
+	// previousObject is the tag object of an annotated tag, used to restore it
+	previousObject string
+}
+
+// Moved reports whether an existing tag was moved to another commit.
+func (c TagChange) Moved() bool {
+	return len(c.Previous) > 0 && c.Previous != c.Commit
+}
+
+// Unchanged reports whether the tag already pointed to the commit.
+func (c TagChange) Unchanged() bool {
+	return len(c.Previous) > 0 && c.Previous == c.Commit
+}
+
+// TagInfo is a tag with the commit it points to. Date is when the tag was
+// created, the date of the commit when the backend does not know it.
+type TagInfo struct {
+	Name   string    `json:"name" yaml:"name"`
+	Commit string    `json:"commit" yaml:"commit"`
+	Date   time.Time `json:"date" yaml:"date"`
+}
+
+// ListTagInfo returns the local tags matching the glob pattern, every tag
+// when the pattern is empty.
+func ListTagInfo(pattern string) ([]TagInfo, error) {
+	output, err := execCommand("git", "for-each-ref",
+		"--format=%(refname:strip=2)%00%(objectname)%00%(*objectname)%00%(creatordate:iso-strict)", "refs/tags")
+	if err != nil {
+		return nil, fmt.Errorf("listing tags (%s): %w", pattern, err)
+	}
+
+	tags := []TagInfo{}
+	for _, line := range splitLines(string(output)) {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 4 || !matchTag(fields[0], pattern) {
+			continue
+		}
+
+		tag := TagInfo{Name: fields[0], Commit: fields[1]}
+		// annotated tags point to the tag object, the commit is dereferenced
+		if len(fields[2]) > 0 {
+			tag.Commit = fields[2]
+		}
+		if tag.Date, err = time.Parse(time.RFC3339, fields[3]); err != nil {
+			return nil, fmt.Errorf("parsing the date of tag %s: %w", tag.Name, err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// TagTarget returns the commit the local tag points to, an empty string when
+// the tag does not exist.
+func TagTarget(name string) (string, error) {
+	_, commit, err := tagRef(name)
+	return commit, err
+}
+
+// tagRef returns the object of the tag (the tag object of annotated tags)
+// and the commit it points to, empty when the tag does not exist.
+func tagRef(name string) (string, string, error) {
+	ref := "refs/tags/" + name
+	output, err := execCommand("git", "for-each-ref", "--format=%(refname) %(objectname) %(*objectname)", ref)
+	if err != nil {
+		return "", "", fmt.Errorf("looking up tag %s: %w", name, err)
+	}
+
+	// for-each-ref also lists the refs below ref, e.g. refs/tags/v1/rc
+	for _, line := range splitLines(string(output)) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != ref {
+			continue
+		}
+		if len(fields) == 3 {
+			return fields[1], fields[2], nil
+		}
+		return fields[1], fields[1], nil
+	}
+	return "", "", nil
+}
+
+// CreateTag creates the local tag at ref (HEAD when empty), annotated with
+// the message when it is not empty and signed when signing is enabled. A
+// tag already pointing to the commit is kept, a tag pointing to another
+// commit is only moved when move is set, otherwise ErrTagExists is returned.
+func CreateTag(tag, ref, message string, move bool) (*TagChange, error) {
+	if len(ref) == 0 {
+		ref = "HEAD"
+	}
+	logger.Info("Creating tag", "tag", tag, "ref", ref, "annotated", len(message) > 0, "signed", SigningEnabled())
+
+	output, err := execCommand("git", "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	object, previous, err := tagRef(tag)
+	if err != nil {
+		return nil, err
+	}
+	change := &TagChange{Tag: tag, Commit: strings.TrimSpace(string(output)), Previous: previous, previousObject: object}
+
+	if change.Unchanged() {
+		logger.Info("Tag already points to the commit", "tag", tag, "commit", change.Commit)
+		return change, nil
+	}
+	if change.Moved() && !move {
+		return nil, tagExistsError(change)
+	}
+
+	args := append(tagArgs(tag, message), change.Commit)
+	if change.Moved() {
+		args = append(args, "--force")
+	}
+	if _, err := execWriteCommand("git", args...); err != nil {
+		return nil, fmt.Errorf("creating tag %q: %w", tag, err)
+	}
+
+	if change.Moved() && !dryrun.Enabled() {
+		auditTagMove(BackendGit, change)
+	}
+	logger.Info("Tag created successfully", "tag", tag)
+	return change, nil
+}
+
+// RestoreTag undoes CreateTag, a new tag is deleted and a moved tag points
+// to its previous commit again.
+func RestoreTag(change *TagChange) error {
+	switch {
+	case change.Unchanged():
+		return nil
+	case len(change.Previous) == 0:
+		return DeleteTag(change.Tag)
+	}
+
+	if _, err := execWriteCommand("git", "update-ref", "refs/tags/"+change.Tag, change.previousObject); err != nil {
+		return fmt.Errorf("restoring tag %q: %w", change.Tag, err)
+	}
+	return nil
+}
+
+func tagExistsError(change *TagChange) error {
+	return fmt.Errorf("tag %s points to %s, not %s: %w", change.Tag, shortHash(change.Previous), shortHash(change.Commit), ErrTagExists)
+}
+
+// auditTagMove records the old and new commit of a moved tag.
+func auditTagMove(backend string, change *TagChange) {
+	auditLogger.Warn("Moved tag",
+		"tag", change.Tag,
+		"old", change.Previous,
+		"new", change.Commit,
+		"backend", backend,
+		"pipeline", os.Getenv("CI_PIPELINE_URL"),
+		"user", os.Getenv("GITLAB_USER_LOGIN"),
+	)
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
@@ -0,0 +1,213 @@
+// Package issuekey extracts the issue tracker key of a release, e.g.
+// "ULT-42", from the branch name, the commit trailers and the titles of the
+// merge requests of the commit.
+package issuekey
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"ulist.app/ult/internal/config"
+	"ulist.app/ult/internal/git"
+)
+
+// ConfigKey is the .ult.yaml key holding the issue key settings.
+const ConfigKey = "issue-keys"
+
+// Sources an issue key is extracted from.
+const (
+	SourceBranch       = "branch"
+	SourceTrailer      = "trailer"
+	SourceMergeRequest = "merge-request"
+)
+
+// Settings configure the issue keys. Projects are the issue tracker project
+// keys, a key is the project followed by a number ("APP-12"). Patterns are
+// additional regular expressions, the key is the "key" group, the first
+// group or the whole match. Trailers are the commit trailer keys holding
+// issues and Order is the precedence of the sources.
+type Settings struct {
+	Projects []string `yaml:"projects"`
+	Patterns []string `yaml:"patterns"`
+	Trailers []string `yaml:"trailers"`
+	Order    []string `yaml:"order"`
+}
+
+// Input is what the issue key is extracted from.
+type Input struct {
+	Branch             string
+	Trailers           []git.Trailer
+	MergeRequestTitles []string
+}
+
+// Match is an extracted key and the source it was found in.
+type Match struct {
+	Key    string `json:"key" yaml:"key"`
+	Source string `json:"source" yaml:"source"`
+}
+
+// Extractor finds issue keys.
+type Extractor struct {
+	settings Settings
+	projects *regexp.Regexp
+	patterns []*regexp.Regexp
+}
+
+var numberRegex = regexp.MustCompile(`^#?(\d+)$`)
+var projectKeyRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// DefaultSettings returns the settings used when none are configured, the
+// "app-12" branches ult always understood.
+func DefaultSettings() Settings {
+	return Settings{
+		Projects: []string{"APP"},
+		Trailers: []string{"Issue"},
+		Order:    []string{SourceBranch, SourceTrailer, SourceMergeRequest},
+	}
+}
+
+// Load reads the settings from the configuration, settings that are not
+// configured keep their default.
+func Load(cfg *config.Config) (*Extractor, error) {
+	configured := Settings{}
+	if _, err := cfg.Decode(ConfigKey, &configured); err != nil {
+		return nil, err
+	}
+
+	settings := DefaultSettings()
+	if configured.Projects != nil {
+		settings.Projects = configured.Projects
+	}
+	if configured.Patterns != nil {
+		settings.Patterns = configured.Patterns
+	}
+	if configured.Trailers != nil {
+		settings.Trailers = configured.Trailers
+	}
+	if configured.Order != nil {
+		settings.Order = configured.Order
+	}
+	return New(settings)
+}
+
+// New returns an extractor for the settings.
+func New(settings Settings) (*Extractor, error) {
+	e := &Extractor{settings: settings}
+	if len(settings.Projects) == 0 && len(settings.Patterns) == 0 {
+		return nil, fmt.Errorf("invalid %s, at least one project or pattern is required", ConfigKey)
+	}
+
+	if len(settings.Projects) > 0 {
+		projects := make([]string, 0, len(settings.Projects))
+		for _, project := range settings.Projects {
+			if !projectKeyRegex.MatchString(project) {
+				return nil, fmt.Errorf("invalid project '%s' in %s", project, ConfigKey)
+			}
+			projects = append(projects, regexp.QuoteMeta(project))
+		}
+		e.projects = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(` + strings.Join(projects, "|") + `)-(\d+)`)
+	}
+
+	for _, pattern := range settings.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern in %s: %w", ConfigKey, err)
+		}
+		e.patterns = append(e.patterns, re)
+	}
+
+	for _, source := range settings.Order {
+		switch source {
+		case SourceBranch, SourceTrailer, SourceMergeRequest:
+		default:
+			return nil, fmt.Errorf("invalid source '%s' in %s order (valid sources: %s, %s, %s)",
+				source, ConfigKey, SourceBranch, SourceTrailer, SourceMergeRequest)
+		}
+	}
+	return e, nil
+}
+
+// Find returns the first issue key in the text.
+func (e *Extractor) Find(text string) (string, bool) {
+	if e.projects != nil {
+		if matches := e.projects.FindStringSubmatch(text); matches != nil {
+			return strings.ToUpper(matches[1]) + "-" + matches[2], true
+		}
+	}
+
+	for _, re := range e.patterns {
+		matches := re.FindStringSubmatch(text)
+		if matches == nil {
+			continue
+		}
+		if i := re.SubexpIndex("key"); i > 0 {
+			return matches[i], true
+		}
+		if len(matches) > 1 {
+			return matches[1], true
+		}
+		return matches[0], true
+	}
+	return "", false
+}
+
+// Extract returns the key of the first source, in the configured order,
+// containing one.
+func (e *Extractor) Extract(in Input) (Match, bool) {
+	for _, source := range e.settings.Order {
+		switch source {
+		case SourceBranch:
+			if key, ok := e.Find(in.Branch); ok {
+				return Match{Key: key, Source: source}, true
+			}
+		case SourceTrailer:
+			for _, trailer := range in.Trailers {
+				if !slices.ContainsFunc(e.settings.Trailers, func(key string) bool { return strings.EqualFold(key, trailer.Key) }) {
+					continue
+				}
+				if key, err := e.Normalize(trailer.Value); err == nil {
+					return Match{Key: key, Source: source}, true
+				}
+			}
+		case SourceMergeRequest:
+			for _, title := range in.MergeRequestTitles {
+				if key, ok := e.Find(title); ok {
+					return Match{Key: key, Source: source}, true
+				}
+			}
+		}
+	}
+	return Match{}, false
+}
+
+// Normalize returns the key given by the user, e.g. with --issue. A bare
+// number ("42" or "#42") belongs to the first project.
+func (e *Extractor) Normalize(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return "", errors.New("issue key cannot be empty")
+	}
+
+	if matches := numberRegex.FindStringSubmatch(value); matches != nil && len(e.settings.Projects) > 0 {
+		return strings.ToUpper(e.settings.Projects[0]) + "-" + matches[1], nil
+	}
+	if key, ok := e.Find(value); ok {
+		return key, nil
+	}
+	return "", fmt.Errorf("'%s' is not an issue key of the projects %s", value, strings.Join(e.settings.Projects, ", "))
+}
+
+// Number returns the number of the key, "ULT-42" is 42, and 0 for keys
+// without a number.
+func Number(key string) int {
+	digits := key[strings.LastIndexFunc(key, func(r rune) bool { return r < '0' || r > '9' })+1:]
+	number, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0
+	}
+	return number
+}
@@ -0,0 +1,159 @@
+package issuekey
+
+import (
+	"testing"
+
+	"ulist.app/ult/internal/git"
+)
+
+func TestFind(t *testing.T) {
+	e, err := New(Settings{Projects: []string{"APP", "ULT", "PAY"}, Patterns: []string{`#(\d+)`}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tt := map[string]string{
+		"feature/app-12":             "APP-12",
+		"fix/ULT-42":                 "ULT-42",
+		"feature/PAY-7-foo":          "PAY-7",
+		"hotfix/pay-0":               "PAY-0",
+		"ult-9":                      "ULT-9",
+		"feat: login (ULT-42)":       "ULT-42",
+		"https://x/browse/ULT-5":     "ULT-5",
+		"fixes #31":                  "31",
+		"feature/myapp-12":           "",
+		"feature/login":              "",
+		"feature/ult-":               "",
+		"release/2025.200 and ULT-1": "ULT-1",
+	}
+
+	for text, want := range tt {
+		got, ok := e.Find(text)
+		if got != want || ok != (len(want) > 0) {
+			t.Errorf("Find(%q) = %q, %t, want %q", text, got, ok, want)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	tt := []struct {
+		name   string
+		order  []string
+		input  Input
+		want   Match
+		wantOk bool
+	}{
+		{
+			name:   "branch first",
+			input:  Input{Branch: "feature/app-12", Trailers: []git.Trailer{{Key: "Issue", Value: "ULT-3"}}},
+			want:   Match{Key: "APP-12", Source: SourceBranch},
+			wantOk: true,
+		},
+		{
+			name:   "trailer",
+			input:  Input{Branch: "main", Trailers: []git.Trailer{{Key: "Signed-off-by", Value: "ULT-1"}, {Key: "issue", Value: "ULT-3"}}},
+			want:   Match{Key: "ULT-3", Source: SourceTrailer},
+			wantOk: true,
+		},
+		{
+			name:   "trailer number",
+			input:  Input{Branch: "main", Trailers: []git.Trailer{{Key: "Issue", Value: "#8"}}},
+			want:   Match{Key: "APP-8", Source: SourceTrailer},
+			wantOk: true,
+		},
+		{
+			name:   "merge request",
+			input:  Input{Branch: "main", MergeRequestTitles: []string{"Draft: login", "ULT-5 login"}},
+			want:   Match{Key: "ULT-5", Source: SourceMergeRequest},
+			wantOk: true,
+		},
+		{
+			name:   "order",
+			order:  []string{SourceMergeRequest, SourceBranch},
+			input:  Input{Branch: "feature/app-12", MergeRequestTitles: []string{"ULT-5 login"}},
+			want:   Match{Key: "ULT-5", Source: SourceMergeRequest},
+			wantOk: true,
+		},
+		{
+			name:  "source not in order",
+			order: []string{SourceBranch},
+			input: Input{Branch: "main", Trailers: []git.Trailer{{Key: "Issue", Value: "ULT-3"}}},
+		},
+		{
+			name:  "none",
+			input: Input{Branch: "main"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			settings := DefaultSettings()
+			settings.Projects = []string{"APP", "ULT"}
+			if tc.order != nil {
+				settings.Order = tc.order
+			}
+			e, err := New(settings)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			got, ok := e.Extract(tc.input)
+			if got != tc.want || ok != tc.wantOk {
+				t.Errorf("Extract() = %v, %t, want %v, %t", got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	e, err := New(Settings{Projects: []string{"ult", "APP"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tt := map[string]string{
+		"42":     "ULT-42",
+		"#42":    "ULT-42",
+		"app-7":  "APP-7",
+		" ULT-1": "ULT-1",
+	}
+	for value, want := range tt {
+		if got, err := e.Normalize(value); err != nil || got != want {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+
+	for _, value := range []string{"", "login", "PAY-3"} {
+		if got, err := e.Normalize(value); err == nil {
+			t.Errorf("Normalize(%q) = %q, want error", value, got)
+		}
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tt := map[string]int{
+		"ULT-42": 42,
+		"APP-0":  0,
+		"31":     31,
+		"ULT":    0,
+		"":       0,
+	}
+	for key, want := range tt {
+		if got := Number(key); got != want {
+			t.Errorf("Number(%q) = %d, want %d", key, got, want)
+		}
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for name, settings := range map[string]Settings{
+		"empty":   {},
+		"project": {Projects: []string{"UL T"}},
+		"pattern": {Patterns: []string{"("}},
+		"order":   {Projects: []string{"ULT"}, Order: []string{"tag"}},
+	} {
+		if _, err := New(settings); err == nil {
+			t.Errorf("New() with invalid %s returned no error", name)
+		}
+	}
+}
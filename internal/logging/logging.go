@@ -0,0 +1,131 @@
+// Package logging is the single logging setup shared by every ult package.
+// Loggers are created with New and always write to stderr, so stdout stays
+// reserved for command results. The root command configures the level and
+// format once through Setup, even for loggers created before it runs.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/charmbracelet/log"
+)
+
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatJSON   Format = "json"
+	FormatLogfmt Format = "logfmt"
+)
+
+var current atomic.Pointer[slog.Handler]
+
+func init() {
+	Setup(Options{})
+}
+
+// Options configures the shared logger.
+type Options struct {
+	// Verbose enables debug messages.
+	Verbose bool
+	// Quiet only shows errors, it takes precedence over Verbose.
+	Quiet  bool
+	Format Format
+	// Writer defaults to stderr.
+	Writer io.Writer
+}
+
+// ParseFormat parses a log format name, an empty name is the text format.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatLogfmt:
+		return FormatLogfmt, nil
+	}
+
+	return FormatText, fmt.Errorf("invalid log format: %s (valid formats: text, json, logfmt)", s)
+}
+
+// Setup replaces the handler used by every logger created with New and by
+// slog.Default. Without flags only warnings and errors are shown.
+func Setup(opts Options) {
+	level := log.WarnLevel
+	if opts.Verbose {
+		level = log.DebugLevel
+	}
+	if opts.Quiet {
+		level = log.ErrorLevel
+	}
+
+	formatter := log.TextFormatter
+	switch opts.Format {
+	case FormatJSON:
+		formatter = log.JSONFormatter
+	case FormatLogfmt:
+		formatter = log.LogfmtFormatter
+	}
+
+	writer := opts.Writer
+	if writer == nil {
+		writer = os.Stderr
+	}
+
+	var handler slog.Handler = log.NewWithOptions(writer, log.Options{
+		Level:           level,
+		Formatter:       formatter,
+		ReportTimestamp: true,
+	})
+	current.Store(&handler)
+
+	slog.SetDefault(slog.New(&dynamicHandler{}))
+}
+
+// New returns a logger for the named component.
+func New(component string) *slog.Logger {
+	return slog.New(&dynamicHandler{}).WithGroup(component)
+}
+
+// dynamicHandler forwards records to the handler configured by the latest
+// Setup call, replaying the attributes and groups added to it.
+type dynamicHandler struct {
+	ops []func(slog.Handler) slog.Handler
+}
+
+func (h *dynamicHandler) target() slog.Handler {
+	handler := *current.Load()
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	return handler
+}
+
+func (h *dynamicHandler) with(op func(slog.Handler) slog.Handler) *dynamicHandler {
+	ops := make([]func(slog.Handler) slog.Handler, 0, len(h.ops)+1)
+	ops = append(ops, h.ops...)
+	return &dynamicHandler{ops: append(ops, op)}
+}
+
+func (h *dynamicHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.target().Enabled(ctx, level)
+}
+
+func (h *dynamicHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.target().Handle(ctx, record)
+}
+
+func (h *dynamicHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *dynamicHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
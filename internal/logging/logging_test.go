@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSetupAppliesToExistingLoggers(t *testing.T) {
+	logger := New("test")
+	t.Cleanup(func() { Setup(Options{}) })
+
+	var buf bytes.Buffer
+	Setup(Options{Writer: &buf})
+	logger.Info("hidden by default")
+	logger.Warn("shown by default")
+	if strings.Contains(buf.String(), "hidden by default") || !strings.Contains(buf.String(), "shown by default") {
+		t.Errorf("default level output = %q", buf.String())
+	}
+
+	buf.Reset()
+	Setup(Options{Writer: &buf, Verbose: true, Format: FormatJSON})
+	logger.Debug("debug message", "key", "value")
+	got := buf.String()
+	if !strings.Contains(got, `"msg":"debug message"`) || !strings.Contains(got, `"key":"value"`) {
+		t.Errorf("verbose json output = %q", got)
+	}
+
+	buf.Reset()
+	Setup(Options{Writer: &buf, Verbose: true, Quiet: true})
+	logger.Warn("quiet hides warnings")
+	if buf.Len() != 0 {
+		t.Errorf("quiet output = %q, want empty", buf.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, input := range []string{"", "text", "json", "LOGFMT"} {
+		if _, err := ParseFormat(input); err != nil {
+			t.Errorf("ParseFormat(%q) error = %v", input, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(\"xml\") was expecting an error")
+	}
+}
@@ -0,0 +1,91 @@
+// Package output renders command results in the format selected with the
+// global --output flag: human readable text (table, the default), JSON or YAML.
+// Results are always written to stdout so they can be piped to other tools.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+)
+
+var (
+	format Format    = FormatTable
+	writer io.Writer = os.Stdout
+)
+
+// ParseFormat parses an output format name, an empty name is the table format.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", FormatTable:
+		return FormatTable, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatYAML:
+		return FormatYAML, nil
+	}
+
+	return FormatTable, fmt.Errorf("invalid output format: %s (valid formats: table, json, yaml)", s)
+}
+
+// Set selects the format used by Print.
+func Set(f Format) {
+	format = f
+}
+
+// Current returns the selected output format.
+func Current() Format {
+	return format
+}
+
+// IsMachineReadable reports whether results are printed as JSON or YAML.
+func IsMachineReadable() bool {
+	return format != FormatTable
+}
+
+// Print writes result in the selected format. For the table format the
+// table function renders the human readable version, when it is nil the
+// result is printed with its default formatting.
+func Print(result any, table func(w io.Writer) error) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(writer)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+
+	case FormatYAML:
+		enc := yaml.NewEncoder(writer)
+		enc.SetIndent(2)
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	if table == nil {
+		_, err := fmt.Fprintln(writer, result)
+		return err
+	}
+	return table(writer)
+}
+
+// Println prints a human readable message, it is only shown in the table
+// format so it never mixes with JSON or YAML results.
+func Println(a ...any) {
+	if IsMachineReadable() {
+		return
+	}
+	fmt.Fprintln(writer, a...)
+}
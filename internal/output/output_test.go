@@ -0,0 +1,71 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+type result struct {
+	Name  string `json:"name" yaml:"name"`
+	Count int    `json:"count" yaml:"count"`
+}
+
+func TestParseFormat(t *testing.T) {
+	tt := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{input: "", want: FormatTable},
+		{input: "table", want: FormatTable},
+		{input: "JSON", want: FormatJSON},
+		{input: "yaml", want: FormatYAML},
+		{input: "xml", wantErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseFormat(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseFormat() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && got != tc.want {
+				t.Errorf("ParseFormat() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	tt := []struct {
+		format Format
+		want   string
+	}{
+		{format: FormatTable, want: "name=ult count=2\n"},
+		{format: FormatJSON, want: "{\n  \"name\": \"ult\",\n  \"count\": 2\n}\n"},
+		{format: FormatYAML, want: "name: ult\ncount: 2\n"},
+	}
+
+	for _, tc := range tt {
+		t.Run(string(tc.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			writer = &buf
+			Set(tc.format)
+			t.Cleanup(func() { Set(FormatTable) })
+
+			r := result{Name: "ult", Count: 2}
+			err := Print(r, func(w io.Writer) error {
+				_, err := fmt.Fprintf(w, "name=%s count=%d\n", r.Name, r.Count)
+				return err
+			})
+			if err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+			if buf.String() != tc.want {
+				t.Errorf("Print() = %q, want %q", buf.String(), tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Package paginate walks the pages of the GitLab API list endpoints, which
+// only return the first page (20 items) unless the next pages are requested.
+package paginate
+
+import (
+	"iter"
+	"strconv"
+
+	"github.com/hashicorp/go-retryablehttp"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// PerPage is the number of items requested per page, the maximum of the
+// GitLab API.
+const PerPage = 100
+
+// Fetch requests the page of a list endpoint selected by the list options,
+// the other options (e.g. a search) are set by the function.
+type Fetch[T any] func(page gitlab.ListOptions) ([]T, *gitlab.Response, error)
+
+// WithPage requests the page for the endpoints whose client method takes
+// no list options, e.g. the merge requests of a commit.
+func WithPage(page gitlab.ListOptions) gitlab.RequestOptionFunc {
+	return func(req *retryablehttp.Request) error {
+		query := req.URL.Query()
+		query.Set("page", strconv.Itoa(page.Page))
+		query.Set("per_page", strconv.Itoa(page.PerPage))
+		req.URL.RawQuery = query.Encode()
+		return nil
+	}
+}
+
+// All iterates over the items of every page. The next page is only
+// requested once the items of the previous one were consumed, so breaking
+// out of the loop stops the requests. An error ends the iteration.
+func All[T any](fetch Fetch[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		page := gitlab.ListOptions{Page: 1, PerPage: PerPage}
+		for {
+			items, resp, err := fetch(page)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			// the last page has no next page
+			if resp == nil || resp.NextPage <= page.Page {
+				return
+			}
+			page.Page = resp.NextPage
+		}
+	}
+}
+
+// Collect returns the items of every page.
+func Collect[T any](fetch Fetch[T]) ([]T, error) {
+	items := []T{}
+	for item, err := range All(fetch) {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Find returns the first item matching, the pages after it are not
+// requested.
+func Find[T any](fetch Fetch[T], match func(T) bool) (T, bool, error) {
+	for item, err := range All(fetch) {
+		if err != nil {
+			var zero T
+			return zero, false, err
+		}
+		if match(item) {
+			return item, true, nil
+		}
+	}
+
+	var zero T
+	return zero, false, nil
+}
@@ -0,0 +1,98 @@
+package paginate
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// pages returns a Fetch serving the pages and the list of requested pages.
+func pages(items ...[]int) (Fetch[int], *[]int) {
+	requested := []int{}
+	return func(page gitlab.ListOptions) ([]int, *gitlab.Response, error) {
+		requested = append(requested, page.Page)
+		if page.PerPage != PerPage {
+			return nil, nil, errors.New("unexpected page size")
+		}
+
+		resp := &gitlab.Response{}
+		if page.Page < len(items) {
+			resp.NextPage = page.Page + 1
+		}
+		return items[page.Page-1], resp, nil
+	}, &requested
+}
+
+func TestCollect(t *testing.T) {
+	fetch, requested := pages([]int{1, 2}, []int{3, 4}, []int{5})
+
+	got, err := Collect(fetch)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if want := []int{1, 2, 3, 4, 5}; !slices.Equal(got, want) {
+		t.Errorf("Collect() = %v, want %v", got, want)
+	}
+	if want := []int{1, 2, 3}; !slices.Equal(*requested, want) {
+		t.Errorf("requested pages = %v, want %v", *requested, want)
+	}
+}
+
+func TestFindStopsEarly(t *testing.T) {
+	fetch, requested := pages([]int{1, 2}, []int{3, 4}, []int{5})
+
+	got, found, err := Find(fetch, func(i int) bool { return i == 3 })
+	if err != nil || !found || got != 3 {
+		t.Errorf("Find() = %d, %t, %v, want 3, true, nil", got, found, err)
+	}
+	if want := []int{1, 2}; !slices.Equal(*requested, want) {
+		t.Errorf("requested pages = %v, want %v", *requested, want)
+	}
+
+	_, found, err = Find(fetch, func(i int) bool { return i == 9 })
+	if err != nil || found {
+		t.Errorf("Find() of a missing item = %t, %v, want false, nil", found, err)
+	}
+}
+
+func TestAllError(t *testing.T) {
+	calls := 0
+	fetch := func(page gitlab.ListOptions) ([]int, *gitlab.Response, error) {
+		calls++
+		if page.Page == 2 {
+			return nil, nil, errors.New("boom")
+		}
+		return []int{1}, &gitlab.Response{NextPage: 2}, nil
+	}
+
+	if _, err := Collect(fetch); err == nil {
+		t.Error("Collect() error = nil, want error")
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestWithPage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("page") + "/" + r.URL.Query().Get("per_page"); got != "3/100" {
+			t.Errorf("page/per_page = %s, want 3/100", got)
+		}
+		json.NewEncoder(w).Encode([]any{})
+	}))
+	t.Cleanup(srv.Close)
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(srv.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = client.Commits.ListMergeRequestsByCommit("1", "abc123", WithPage(gitlab.ListOptions{Page: 3, PerPage: PerPage}))
+	if err != nil {
+		t.Fatalf("ListMergeRequestsByCommit() error = %v", err)
+	}
+}
@@ -3,23 +3,21 @@ package playstore
 import (
 	"context"
 	"fmt"
-	"log/slog"
-	"os"
 
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/androidpublisher/v3"
 	"google.golang.org/api/option"
+	"ulist.app/ult/internal/logging"
 )
 
 var (
-	// logger = log.NewWithOptions(os.Stdout, log.Options{
-	// 	Level:           log.DebugLevel,
-	// 	ReportTimestamp: true,
-	// })
-
-	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	logger = logging.New("playstore")
 )
 
+// DefaultPackageName is the package of the uList app, used when no
+// --play-package is configured.
+const DefaultPackageName = "app.ulist"
+
 // Release is a single release on a Play Store track.
 type Release struct {
 	Name         string
@@ -73,6 +71,10 @@ func GetProductionReleases(credFile []byte, packageName string) ([]Release, erro
 	return releases, nil
 }
 
+// GetVersionFromLatestRelease returns the greatest version code of the
+// production and internal testing tracks. Version codes are plain integers
+// (the build number of the uploaded version), so they are compared as
+// numbers and not with the version package.
 func GetVersionFromLatestRelease(credFile []byte, packageName string) (int64, error) {
 	var latestCode int64
 
@@ -0,0 +1,201 @@
+// Package pubspec reads and updates the version of a Flutter pubspec.yaml.
+// The version is located through the YAML node tree, so only the top-level
+// `version:` key is considered, and it is updated in place keeping comments,
+// formatting and line endings of the rest of the file untouched.
+package pubspec
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/version"
+)
+
+// DefaultPath is the pubspec.yaml of the project in the working directory.
+const DefaultPath = "pubspec.yaml"
+
+const versionKey = "version"
+
+var (
+	logger = logging.New("pubspec")
+)
+
+// File is a pubspec.yaml loaded in memory.
+type File struct {
+	Path    string
+	content []byte
+	mode    os.FileMode
+
+	// position of the version value, line and column are 1-based
+	line   int
+	column int
+	// raw is the version value as written in the file, including quotes
+	raw   string
+	value string
+	style yaml.Style
+}
+
+// Read loads the pubspec.yaml at path and locates its version.
+func Read(path string) (*File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("getting file info: %w", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	f, err := Parse(path, content)
+	if err != nil {
+		return nil, err
+	}
+	f.mode = info.Mode()
+	return f, nil
+}
+
+// ReadVersion loads the pubspec.yaml at path and parses its version.
+func ReadVersion(path string) (*version.Version, error) {
+	f, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	return f.Version()
+}
+
+// Parse locates the top-level version of the pubspec content. Path is only
+// used in messages and by Write.
+func Parse(path string, content []byte) (*File, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing %s: expected a mapping at the top level", path)
+	}
+
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value != versionKey {
+			continue
+		}
+		if value.Kind != yaml.ScalarNode || len(value.Value) == 0 {
+			return nil, fmt.Errorf("%s: '%s' at line %d is not a version string", path, versionKey, key.Line)
+		}
+
+		f := &File{
+			Path:    path,
+			content: content,
+			mode:    0o644,
+			line:    value.Line,
+			column:  value.Column,
+			value:   value.Value,
+			style:   value.Style,
+		}
+		raw, err := f.token()
+		if err != nil {
+			return nil, err
+		}
+		f.raw = raw
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("version not found in %s", path)
+}
+
+// Line returns the line number of the version in the file.
+func (f *File) Line() int {
+	return f.line
+}
+
+// Version parses the version value with the default version scheme.
+func (f *File) Version() (*version.Version, error) {
+	v, err := version.Parse(f.value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing version in %s: %w", f.Path, err)
+	}
+	return v, nil
+}
+
+// SetVersion replaces the version value keeping its quoting style.
+func (f *File) SetVersion(v version.Version) {
+	value := v.String()
+
+	raw := value
+	switch f.style {
+	case yaml.SingleQuotedStyle:
+		raw = "'" + value + "'"
+	case yaml.DoubleQuotedStyle:
+		raw = `"` + value + `"`
+	}
+
+	lines := bytes.SplitAfter(f.content, []byte("\n"))
+	line := []rune(string(lines[f.line-1]))
+	start := f.column - 1
+	end := start + len([]rune(f.raw))
+
+	updated := string(line[:start]) + raw + string(line[end:])
+	lines[f.line-1] = []byte(updated)
+
+	f.content = bytes.Join(lines, nil)
+	f.raw = raw
+	f.value = value
+}
+
+// Bytes returns the content of the file including any version change.
+func (f *File) Bytes() []byte {
+	return f.content
+}
+
+// Write saves the file keeping its original permissions. In dry-run mode
+// the updated version line is only printed.
+func (f *File) Write() error {
+	if dryrun.Enabled() {
+		dryrun.Printf("write %s line %d: %s: %s", f.Path, f.line, versionKey, f.value)
+		return nil
+	}
+
+	logger.Debug("writing pubspec file", "path", f.Path, "bytes", len(f.content))
+	if err := os.WriteFile(f.Path, f.content, f.mode); err != nil {
+		return fmt.Errorf("writing %s: %w", f.Path, err)
+	}
+	return nil
+}
+
+// token returns the version value as written in the file, so it can be
+// replaced without touching the rest of the line (e.g. a trailing comment).
+func (f *File) token() (string, error) {
+	lines := bytes.SplitAfter(f.content, []byte("\n"))
+	if f.line < 1 || f.line > len(lines) {
+		return "", fmt.Errorf("%s: version line %d out of range", f.Path, f.line)
+	}
+
+	line := []rune(string(lines[f.line-1]))
+	if f.column < 1 || f.column > len(line) {
+		return "", fmt.Errorf("%s: version column %d out of range", f.Path, f.column)
+	}
+	rest := string(line[f.column-1:])
+
+	switch f.style {
+	case yaml.SingleQuotedStyle, yaml.DoubleQuotedStyle:
+		quote := rest[:1]
+		end := strings.Index(rest[1:], quote)
+		if end < 0 {
+			return "", fmt.Errorf("%s: unterminated version string at line %d", f.Path, f.line)
+		}
+		return rest[:end+2], nil
+	case 0:
+		if strings.HasPrefix(rest, f.value) {
+			return f.value, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: unsupported version format at line %d, use a plain or quoted string", f.Path, f.line)
+}
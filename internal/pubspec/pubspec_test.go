@@ -0,0 +1,154 @@
+package pubspec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ulist.app/ult/internal/version"
+)
+
+func TestSetVersion(t *testing.T) {
+	newVersion := version.Version{Year: 2025, Major: 200, Minor: 2, Build: 1}
+
+	tt := []struct {
+		name    string
+		content string
+		want    string
+		line    int
+	}{
+		{
+			name:    "plain",
+			content: "name: app\nversion: 2025.200.01+04\n",
+			want:    "name: app\nversion: 2025.200.02+01\n",
+			line:    2,
+		},
+		{
+			name: "keeps comments and formatting",
+			content: "# app version 2024.100.01+01\n" +
+				"name: app\n" +
+				"\n" +
+				"version:   2025.200.01+04 # bumped by ci\n" +
+				"environment:\n" +
+				"  sdk: '>=3.0.0 <4.0.0'\n",
+			want: "# app version 2024.100.01+01\n" +
+				"name: app\n" +
+				"\n" +
+				"version:   2025.200.02+01 # bumped by ci\n" +
+				"environment:\n" +
+				"  sdk: '>=3.0.0 <4.0.0'\n",
+			line: 4,
+		},
+		{
+			name: "ignores nested versions",
+			content: "name: app\n" +
+				"dependencies:\n" +
+				"  foo:\n" +
+				"    version: 2024.100.01+01\n" +
+				"  bar: 2024.100.02+01\n" +
+				"version: 2025.200.01+04\n",
+			want: "name: app\n" +
+				"dependencies:\n" +
+				"  foo:\n" +
+				"    version: 2024.100.01+01\n" +
+				"  bar: 2024.100.02+01\n" +
+				"version: 2025.200.02+01\n",
+			line: 6,
+		},
+		{
+			name:    "single quoted",
+			content: "version: '2025.200.01+04'\n",
+			want:    "version: '2025.200.02+01'\n",
+			line:    1,
+		},
+		{
+			name:    "double quoted",
+			content: "version: \"2025.200.01+04\"\n",
+			want:    "version: \"2025.200.02+01\"\n",
+			line:    1,
+		},
+		{
+			name:    "crlf without trailing newline",
+			content: "name: app\r\nversion: 2025.200.01+04\r\npublish_to: none",
+			want:    "name: app\r\nversion: 2025.200.02+01\r\npublish_to: none",
+			line:    2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Parse(DefaultPath, []byte(tc.content))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			current, err := f.Version()
+			if err != nil {
+				t.Fatalf("Version() error = %v", err)
+			}
+			if current.String() != "2025.200.01+04" {
+				t.Errorf("Version() = %s, want 2025.200.01+04", current)
+			}
+			if f.Line() != tc.line {
+				t.Errorf("Line() = %d, want %d", f.Line(), tc.line)
+			}
+
+			f.SetVersion(newVersion)
+			if got := string(f.Bytes()); got != tc.want {
+				t.Errorf("Bytes() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tt := []struct {
+		name    string
+		content string
+	}{
+		{name: "missing version", content: "name: app\nenvironment:\n  version: 2025.200.01+04\n"},
+		{name: "empty version", content: "name: app\nversion:\n"},
+		{name: "not a mapping", content: "- 2025.200.01+04\n"},
+		{name: "invalid yaml", content: "name: [app\n"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Parse(DefaultPath, []byte(tc.content)); err == nil {
+				t.Error("Parse() expected error")
+			}
+		})
+	}
+}
+
+func TestWriteKeepsPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	if err := os.WriteFile(path, []byte("name: app\nversion: 2025.200.01+04\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	f.SetVersion(version.Version{Year: 2025, Major: 200, Minor: 1, Build: 5})
+	if err := f.Write(); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	got, err := ReadVersion(path)
+	if err != nil {
+		t.Fatalf("ReadVersion() error = %v", err)
+	}
+	if got.String() != "2025.200.01+05" {
+		t.Errorf("ReadVersion() = %s, want 2025.200.01+05", got)
+	}
+}
@@ -7,9 +7,15 @@ import (
 	"time"
 
 	"ulist.app/ult/internal/assignee"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/logging"
 	"ulist.app/ult/internal/version"
 )
 
+var (
+	logger = logging.New("release")
+)
+
 type Release struct {
 	Branch         string
 	Assignee       assignee.Assignee
@@ -17,18 +23,22 @@ type Release struct {
 	Commit         string
 	Date           time.Time
 	IssueTrackerID int
-	Version        version.Version
+	// IssueKey is the issue tracker key, e.g. "ULT-42", IssueTrackerID is
+	// its number.
+	IssueKey string
+	Version  version.Version
 }
 
 func (r Release) String() string {
 	return fmt.Sprintf(
-		"Branch: %s, Assignee: %s, Description: %s, Commit: %s, Date: %s, Issue tracker ID: %d, Version: %s, Bump: %d",
+		"Branch: %s, Assignee: %s, Description: %s, Commit: %s, Date: %s, Issue tracker ID: %d, Issue key: %s, Version: %s, Bump: %d",
 		r.Branch,
 		r.Assignee,
 		r.Description,
 		r.Commit,
 		r.Date,
 		r.IssueTrackerID,
+		r.IssueKey,
 		r.Version.StringNoBuild(),
 		r.Bump(),
 	)
@@ -70,21 +80,30 @@ func SaveVersion(db *sql.DB, version version.Version) (int, error) {
 		return -1, errors.New("database connection is nil")
 	}
 
+	return saveVersion(db, version)
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func saveVersion(db queryer, version version.Version) (int, error) {
 	query := `
   WITH insert_attempt AS (
-    INSERT INTO versions (year, major, minor)
-    VALUES ($1, $2, $3)
+    INSERT INTO versions (year, major, minor, pre_release)
+    VALUES ($1, $2, $3, $4)
     ON CONFLICT DO NOTHING
     RETURNING id
   )
   SELECT id FROM insert_attempt
   UNION ALL
-  SELECT id FROM versions WHERE year = $1 AND major = $2 AND minor = $3
+  SELECT id FROM versions WHERE year = $1 AND major = $2 AND minor = $3 AND pre_release = $4
   LIMIT 1;
   `
 
 	var id int
-	err := db.QueryRow(query, version.Year, version.Major, version.Minor).Scan(&id)
+	err := db.QueryRow(query, version.Year, version.Major, version.Minor, version.PreRelease).Scan(&id)
 	if err != nil {
 		return -1, fmt.Errorf("failed to create version in database: %w", err)
 	}
@@ -92,11 +111,9 @@ func SaveVersion(db *sql.DB, version version.Version) (int, error) {
 	return id, nil
 }
 
+// SaveRelease inserts the release, creating its assignee and version rows when needed.
+// In dry-run mode the release is only printed and the database is never touched.
 func SaveRelease(db *sql.DB, release *Release) error {
-	if db == nil {
-		return errors.New("database connection is nil")
-	}
-
 	if release == nil {
 		return errors.New("release is nil")
 	}
@@ -105,6 +122,15 @@ func SaveRelease(db *sql.DB, release *Release) error {
 		return errors.New("branch is required")
 	}
 
+	if dryrun.Enabled() {
+		dryrun.Printf("insert release into database: %s", release)
+		return nil
+	}
+
+	if db == nil {
+		return errors.New("database connection is nil")
+	}
+
 	assigneeID, err := SaveAssignee(db, release.Assignee)
 	if err != nil {
 		return err
@@ -124,9 +150,10 @@ func SaveRelease(db *sql.DB, release *Release) error {
     commit,
 		date,
 		issue_tracker_id,
+		issue_key,
 		version_id,
 		bump
-	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 
 	err = db.QueryRow(
 		query,
@@ -136,6 +163,7 @@ func SaveRelease(db *sql.DB, release *Release) error {
 		release.Commit,
 		release.Date,
 		release.IssueTrackerID,
+		release.IssueKey,
 		versionID,
 		release.Bump(),
 	).Err()
@@ -143,28 +171,16 @@ func SaveRelease(db *sql.DB, release *Release) error {
 		return fmt.Errorf("failed to create release in database: %w", err)
 	}
 
-	return nil
-}
+	// the build number is now owned by the release
+	if err := deleteReservation(db, versionID, release.Bump()); err != nil {
+		logger.Warn("failed to delete build reservation", "version", release.Version, "error", err)
+	}
 
-func FetchReleases() []*Release {
-	return []*Release{}
+	return nil
 }
 
-func FetchLatestRelease(db *sql.DB) (*Release, error) {
-	var branch string
-	var assigneeName string
-	var assigneeEmail string
-	var description string
-	var commit string
-	var date string
-	var issueTrackerId int
-	var year int
-	var major int
-	var minor int
-	var bump int
-
-	query := `
-  SELECT
+// releaseColumns are the columns of a release read by scanRelease.
+const releaseColumns = `
       r.branch,
       a.name,
       a.email,
@@ -172,26 +188,114 @@ func FetchLatestRelease(db *sql.DB) (*Release, error) {
       r.commit,
       r.date,
       r.issue_tracker_id,
+      r.issue_key,
       v.year,
       v.major,
       v.minor,
+      v.pre_release,
       r.bump
   FROM
       releases r
   JOIN
       versions v ON r.version_id = v.id
   JOIN
-      assignees a ON r.assignee_id = a.id
-  ORDER BY
-      v.year DESC,
-      v.major DESC,
-      v.minor DESC,
-      r.bump DESC
-  LIMIT 1;`
-	err := db.QueryRow(query).Scan(&branch, &assigneeName, &assigneeEmail, &description, &commit, &date, &issueTrackerId, &year, &major, &minor, &bump)
+      assignees a ON r.assignee_id = a.id`
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// FetchReleases returns every release recorded in the database.
+func FetchReleases(db *sql.DB) ([]*Release, error) {
+	if db == nil {
+		return nil, errors.New("database connection is nil")
+	}
+
+	rows, err := db.Query(`SELECT` + releaseColumns + `;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases from database: %w", err)
+	}
+	defer rows.Close()
+
+	releases := []*Release{}
+	for rows.Next() {
+		release, err := scanRelease(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read release from database: %w", err)
+		}
+		releases = append(releases, release)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch releases from database: %w", err)
+	}
+	return releases, nil
+}
+
+// FetchLatestRelease returns the release with the greatest version. The
+// pre-releases can't be ordered by SQL (rc.10 is greater than rc.9), so the
+// releases of the greatest year, major and minor are compared with version.Max.
+func FetchLatestRelease(db *sql.DB) (*Release, error) {
+	query := `
+  SELECT` + releaseColumns + `
+  WHERE (v.year, v.major, v.minor) = (
+      SELECT v.year, v.major, v.minor
+      FROM releases r
+      JOIN versions v ON r.version_id = v.id
+      ORDER BY v.year DESC, v.major DESC, v.minor DESC
+      LIMIT 1
+  );`
+	rows, err := db.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch latest release from database: %w", err)
 	}
+	defer rows.Close()
+
+	releases := []*Release{}
+	versions := []version.Version{}
+	for rows.Next() {
+		release, err := scanRelease(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read release from database: %w", err)
+		}
+		releases = append(releases, release)
+		versions = append(versions, release.Version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch latest release from database: %w", err)
+	}
+
+	latest := version.Max(versions...)
+	if latest == nil {
+		return nil, fmt.Errorf("failed to fetch latest release from database: %w", sql.ErrNoRows)
+	}
+	for _, release := range releases {
+		if release.Version.Equal(*latest) {
+			return release, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to fetch latest release from database: %w", sql.ErrNoRows)
+}
+
+func scanRelease(row scanner) (*Release, error) {
+	var branch string
+	var assigneeName string
+	var assigneeEmail string
+	var description string
+	var commit string
+	var date string
+	var issueTrackerId int
+	var issueKey string
+	var year int
+	var major int
+	var minor int
+	var preRelease string
+	var bump int
+
+	err := row.Scan(&branch, &assigneeName, &assigneeEmail, &description, &commit, &date, &issueTrackerId, &issueKey, &year, &major, &minor, &preRelease, &bump)
+	if err != nil {
+		return nil, err
+	}
 
 	dateTime, err := time.Parse("2006-01-02T15:04:05Z0700", date)
 	if err != nil {
@@ -204,7 +308,15 @@ func FetchLatestRelease(db *sql.DB) (*Release, error) {
 		Commit:         commit,
 		Date:           dateTime,
 		IssueTrackerID: issueTrackerId,
-		Version:        version.Version{Year: year, Major: major, Minor: minor, Build: bump},
+		IssueKey:       issueKey,
+		Version: version.Version{
+			Year:       year,
+			Major:      major,
+			Minor:      minor,
+			PreRelease: preRelease,
+			Build:      bump,
+			Scheme:     version.Default(),
+		},
 	}
 	return release, nil
 }
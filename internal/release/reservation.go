@@ -0,0 +1,120 @@
+package release
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/version"
+)
+
+// reservationLock is the key of the transaction advisory lock serializing
+// build reservations between concurrent pipelines.
+const reservationLock = "ult_build_reservation"
+
+// DefaultReservationTTL is how long an unused reservation holds its build.
+const DefaultReservationTTL = 24 * time.Hour
+
+// Reservation is a build number claimed by a pipeline before its release
+// is saved. Reservations that are not turned into a release expire.
+type Reservation struct {
+	Version    version.Version
+	Pipeline   string
+	Commit     string
+	ReservedAt time.Time
+	ExpiresAt  time.Time
+}
+
+func (r Reservation) String() string {
+	return fmt.Sprintf(
+		"Version: %s, Pipeline: %s, Commit: %s, Expires at: %s",
+		r.Version,
+		r.Pipeline,
+		r.Commit,
+		r.ExpiresAt.Format(time.RFC3339),
+	)
+}
+
+// ReserveBuild atomically claims a build number for the version: the build of
+// the given version, or the build after every release and active reservation
+// of the version when that one is greater. Expired reservations are released
+// before claiming.
+// In dry-run mode the reservation is only printed and the database is never touched.
+func ReserveBuild(db *sql.DB, v version.Version, pipeline, commit string, ttl time.Duration) (*Reservation, error) {
+	reservation := &Reservation{
+		Version:    v,
+		Pipeline:   pipeline,
+		Commit:     commit,
+		ReservedAt: time.Now(),
+		ExpiresAt:  time.Now().Add(ttl),
+	}
+
+	if dryrun.Enabled() {
+		dryrun.Printf("reserve build in database: %s", reservation)
+		return reservation, nil
+	}
+
+	if db == nil {
+		return nil, errors.New("database connection is nil")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("starting build reservation transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// the lock is released when the transaction ends
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, reservationLock); err != nil {
+		return nil, fmt.Errorf("acquiring build reservation lock: %w", err)
+	}
+
+	expired, err := tx.Exec(`DELETE FROM build_reservations WHERE expires_at < now()`)
+	if err != nil {
+		return nil, fmt.Errorf("releasing expired build reservations: %w", err)
+	}
+	if count, err := expired.RowsAffected(); err == nil && count > 0 {
+		logger.Info("released expired build reservations", "count", count)
+	}
+
+	versionID, err := saveVersion(tx, v)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+  SELECT GREATEST($2::INTEGER, COALESCE(MAX(bump), 0) + 1)
+  FROM (
+    SELECT bump FROM releases WHERE version_id = $1
+    UNION ALL
+    SELECT bump FROM build_reservations WHERE version_id = $1
+  ) AS taken;`
+	var build int
+	if err := tx.QueryRow(query, versionID, v.Build).Scan(&build); err != nil {
+		return nil, fmt.Errorf("finding next free build: %w", err)
+	}
+
+	query = `
+  INSERT INTO build_reservations (version_id, bump, pipeline, commit, expires_at)
+  VALUES ($1, $2, $3, $4, now() + make_interval(secs => $5))
+  RETURNING reserved_at, expires_at;`
+	err = tx.QueryRow(query, versionID, build, pipeline, commit, ttl.Seconds()).
+		Scan(&reservation.ReservedAt, &reservation.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create build reservation in database: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing build reservation: %w", err)
+	}
+
+	reservation.Version.Build = build
+	return reservation, nil
+}
+
+func deleteReservation(db *sql.DB, versionID, build int) error {
+	_, err := db.Exec(`DELETE FROM build_reservations WHERE version_id = $1 AND bump = $2`, versionID, build)
+	return err
+}
@@ -0,0 +1,132 @@
+// Package remote changes the version of a branch through a git.Repository,
+// used by --remote to change it in the GitLab project without a checkout.
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v3"
+	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/pubspec"
+	"ulist.app/ult/internal/version"
+	"ulist.app/ult/internal/versionsync"
+)
+
+// ErrUntagged is returned when the version commit was made but could not be
+// tagged.
+var ErrUntagged = errors.New("version commit was left untagged")
+
+var (
+	logger = logging.New("remote")
+)
+
+// Open returns the GitLab project selected by --project-id at the branch,
+// authenticated with --token. Files read from it are committed back only if
+// they did not change since, see git.Gitlab.WriteFiles.
+func Open(cmd *cli.Command, branch string) (*git.Gitlab, error) {
+	client, err := core.NewGitlabClient(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("a token must be provided '--token=my-token' to use the GitLab API: %w", err)
+	}
+	projectID, err := core.GetProjectID(cmd)
+	if err != nil {
+		return nil, errors.New("a projectId must be provided '--project-id=000000000' to use the GitLab API")
+	}
+	return git.NewGitlab(client, projectID, branch), nil
+}
+
+// ReadPubspec reads and parses the pubspec.yaml at path on the current
+// branch of the repository.
+func ReadPubspec(repo git.Repository, path string) (*pubspec.File, error) {
+	content, err := repo.ReadFile("", path)
+	if errors.Is(err, os.ErrNotExist) {
+		branch, _ := repo.CurrentBranch()
+		return nil, fmt.Errorf("%s not found at %s", path, branch)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pubspec.Parse(path, content)
+}
+
+// UpdateVersion sets the version of the pubspec read from the repository and
+// of the sync targets, and commits the files that changed to the current
+// branch. Returns an empty commit ID when every file already has the version
+// or in dry-run mode.
+func UpdateVersion(repo git.Repository, file *pubspec.File, targets []versionsync.Target, v version.Version, message string) (string, error) {
+	before := file.Bytes()
+	file.SetVersion(v)
+
+	files := map[string][]byte{}
+	if !bytes.Equal(before, file.Bytes()) {
+		files[file.Path] = file.Bytes()
+	}
+
+	changes, err := versionsync.PlanWith(reader(repo), targets, v)
+	if err != nil {
+		return "", err
+	}
+	for _, change := range changes {
+		if change.Changed() {
+			files[change.Path] = change.After
+		}
+	}
+
+	if len(files) == 0 {
+		logger.Info("Version is already up to date", "version", v)
+		return "", nil
+	}
+	commit, err := repo.WriteFiles("", message, files)
+	if err != nil {
+		return "", err
+	}
+	return commit.Hash, nil
+}
+
+// reader reads the files of the current branch of the repository.
+func reader(repo git.Repository) versionsync.Reader {
+	return func(path string) ([]byte, bool, error) {
+		content, err := repo.ReadFile("", path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return content, true, nil
+	}
+}
+
+// TagCommit creates the tag at the version commit, annotated when the
+// message is not empty, an existing tag is only moved when move is set.
+// When the tag can not be created the commit is left on the branch and the
+// error wraps ErrUntagged: the Commits API can not revert with a [skip ci]
+// message, so a revert would start a pipeline of its own.
+func TagCommit(repo git.Repository, tagName, commitID, message string, move bool) (*git.TagChange, error) {
+	if dryrun.Enabled() {
+		dryrun.Printf("create tag %s at %s with message %q", tagName, withDefault(commitID, "the new commit"), message)
+		return &git.TagChange{Tag: tagName, Commit: commitID}, nil
+	}
+
+	tag, err := repo.CreateTag(tagName, commitID, message, move)
+	if err != nil {
+		branch, _ := repo.CurrentBranch()
+		logger.Error("Version commit is not tagged", "commit", commitID, "branch", branch, "tag", tagName, "error", err)
+		return nil, fmt.Errorf("%w, commit %s on %s: creating tag %s: %w", ErrUntagged, commitID, branch, tagName, err)
+	}
+	logger.Info("Created tag", "tag", tagName, "commit", commitID)
+	return tag, nil
+}
+
+func withDefault(value, fallback string) string {
+	if len(value) == 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,152 @@
+package remote
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/version"
+	"ulist.app/ult/internal/versionsync"
+)
+
+const pubspecContent = "name: app\nversion: 2025.200.01+04 # ci\n"
+
+// fakeGitlab serves pubspec.yaml and records the commits, answering with
+// the response of GitLab when conflict is set.
+func fakeGitlab(t *testing.T, conflict bool, commits *[]gitlab.CreateCommitOptions) *gitlab.Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/1/repository/files/{path}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("path") != "pubspec.yaml" {
+			http.Error(w, `{"message":"404 File Not Found"}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"file_path":      "pubspec.yaml",
+			"encoding":       "base64",
+			"content":        base64.StdEncoding.EncodeToString([]byte(pubspecContent)),
+			"last_commit_id": "abc123",
+		})
+	})
+	mux.HandleFunc("POST /api/v4/projects/1/repository/commits", func(w http.ResponseWriter, r *http.Request) {
+		if conflict {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"You are attempting to update a file that has changed since you started editing it."}`))
+			return
+		}
+		var opts gitlab.CreateCommitOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			t.Errorf("decoding commit: %v", err)
+		}
+		*commits = append(*commits, opts)
+		json.NewEncoder(w).Encode(map[string]string{"id": "def456"})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(srv.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestUpdateVersion(t *testing.T) {
+	commits := []gitlab.CreateCommitOptions{}
+	repo := git.NewGitlab(fakeGitlab(t, false, &commits), "1", "main")
+	targets := []versionsync.Target{{Path: "lib/version.g.dart", Kind: versionsync.KindDart, Template: "const v = '{version}';\n"}}
+	v := version.Version{Year: 2025, Major: 200, Minor: 1, Build: 5}
+
+	file, err := ReadPubspec(repo, "pubspec.yaml")
+	if err != nil {
+		t.Fatalf("ReadPubspec() error = %v", err)
+	}
+	commitID, err := UpdateVersion(repo, file, targets, v, "bump version [skip ci]")
+	if err != nil {
+		t.Fatalf("UpdateVersion() error = %v", err)
+	}
+	if commitID != "def456" {
+		t.Errorf("UpdateVersion() = %q, want def456", commitID)
+	}
+
+	if len(commits) != 1 {
+		t.Fatalf("got %d commits, want 1", len(commits))
+	}
+	actions := commits[0].Actions
+	if len(actions) != 2 {
+		t.Fatalf("got %d actions, want 2", len(actions))
+	}
+
+	dart, pubspec := actions[0], actions[1]
+	if *dart.Action != gitlab.FileCreate || dart.LastCommitID != nil {
+		t.Errorf("lib/version.g.dart action = %s, want create without last_commit_id", *dart.Action)
+	}
+	if *pubspec.Action != gitlab.FileUpdate || pubspec.LastCommitID == nil || *pubspec.LastCommitID != "abc123" {
+		t.Errorf("pubspec.yaml action = %s, want update with last_commit_id abc123", *pubspec.Action)
+	}
+	if !strings.Contains(*pubspec.Content, "version: 2025.200.01+05 # ci") {
+		t.Errorf("pubspec.yaml content = %q", *pubspec.Content)
+	}
+}
+
+func TestUpdateVersionConflict(t *testing.T) {
+	repo := git.NewGitlab(fakeGitlab(t, true, nil), "1", "main")
+	v := version.Version{Year: 2025, Major: 200, Minor: 1, Build: 5}
+
+	file, err := ReadPubspec(repo, "pubspec.yaml")
+	if err != nil {
+		t.Fatalf("ReadPubspec() error = %v", err)
+	}
+	_, err = UpdateVersion(repo, file, nil, v, "bump version [skip ci]")
+	if !errors.Is(err, git.ErrConflict) {
+		t.Errorf("UpdateVersion() error = %v, want git.ErrConflict", err)
+	}
+}
+
+func TestUpdateVersionUnchanged(t *testing.T) {
+	commits := []gitlab.CreateCommitOptions{}
+	repo := git.NewGitlab(fakeGitlab(t, false, &commits), "1", "main")
+	v := version.Version{Year: 2025, Major: 200, Minor: 1, Build: 4}
+
+	file, err := ReadPubspec(repo, "pubspec.yaml")
+	if err != nil {
+		t.Fatalf("ReadPubspec() error = %v", err)
+	}
+	commitID, err := UpdateVersion(repo, file, nil, v, "bump version [skip ci]")
+	if err != nil || len(commitID) > 0 || len(commits) > 0 {
+		t.Errorf("UpdateVersion() = %q, %v, want no commit", commitID, err)
+	}
+}
+
+func TestTagCommitFails(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/1/repository/tags/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"404 Tag Not Found"}`, http.StatusNotFound)
+	})
+	mux.HandleFunc("POST /api/v4/projects/1/repository/tags", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Tag name invalid"}`, http.StatusBadRequest)
+	})
+	mux.HandleFunc("POST /api/v4/projects/1/repository/commits/{sha}/revert", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("commit %s was reverted", r.PathValue("sha"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(srv.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = TagCommit(git.NewGitlab(client, "1", "main"), "2025.200.01+05", "def456", "", false)
+	if !errors.Is(err, ErrUntagged) {
+		t.Fatalf("TagCommit() error = %v, want ErrUntagged", err)
+	}
+	if !strings.Contains(err.Error(), "def456") {
+		t.Errorf("TagCommit() error = %v, want the untagged commit", err)
+	}
+}
@@ -4,42 +4,88 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/paginate"
 )
 
-func secureFileString(file *gitlab.SecureFile) string {
-	return fmt.Sprintf("id: %d, name: %s, created at: %s, expires at: %s, checksum: %s", file.ID, file.Name, file.CreatedAt, file.ExpiresAt, file.Checksum)
+// File is the output schema of a GitLab secure file.
+type File struct {
+	ID        int        `json:"id" yaml:"id"`
+	Name      string     `json:"name" yaml:"name"`
+	CreatedAt *time.Time `json:"created_at" yaml:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at" yaml:"expires_at"`
+	Checksum  string     `json:"checksum" yaml:"checksum"`
 }
 
-func PrintSecureFile(file *gitlab.SecureFile, onlyId bool, targetName string) {
-	if targetName != "" && targetName != file.Name {
-		return
-	}
+func (f File) String() string {
+	return fmt.Sprintf("id: %d, name: %s, created at: %s, expires at: %s, checksum: %s", f.ID, f.Name, f.CreatedAt, f.ExpiresAt, f.Checksum)
+}
 
-	if onlyId {
-		fmt.Println(file.ID)
-	} else {
-		fmt.Println(secureFileString(file))
+// FilterFiles converts the secure files into their output schema, keeping only
+// the ones named targetName (all of them when targetName is empty).
+func FilterFiles(files []*gitlab.SecureFile, targetName string) []File {
+	filtered := make([]File, 0, len(files))
+	for _, file := range files {
+		if targetName != "" && targetName != file.Name {
+			continue
+		}
+
+		filtered = append(filtered, File{
+			ID:        file.ID,
+			Name:      file.Name,
+			CreatedAt: file.CreatedAt,
+			ExpiresAt: file.ExpiresAt,
+			Checksum:  file.Checksum,
+		})
 	}
+
+	return filtered
 }
 
-func FetchAll(projectId string, token string) ([]*gitlab.SecureFile, *gitlab.Client, error) {
-	appRepo, err := gitlab.NewClient(token)
+// FetchAll returns the secure files of every page.
+func FetchAll(client *gitlab.Client, projectId string) ([]*gitlab.SecureFile, error) {
+	files, err := paginate.Collect(listSecureFiles(client, projectId))
 	if err != nil {
-		return nil, nil, fmt.Errorf("Failed to create client: %v", err)
+		return nil, fmt.Errorf("Error fetching secure files from gitlab: %v", err)
 	}
 
-	opt := &gitlab.ListProjectSecureFilesOptions{}
-	files, _, err := appRepo.SecureFiles.ListProjectSecureFiles(projectId, opt)
-	if err != nil {
-		return nil, nil, fmt.Errorf("Error fetching secure files from gitlab: %v", err)
+	return files, nil
+}
+
+// FindByName returns the secure file named targetName, nil when there is
+// none. GitLab allows several files with the same name, the last one listed
+// wins as it did before the list was paginated. The API can not filter by
+// name, so every page is listed.
+func FindByName(client *gitlab.Client, projectId string, targetName string) (*gitlab.SecureFile, error) {
+	var found *gitlab.SecureFile
+	for file, err := range paginate.All(listSecureFiles(client, projectId)) {
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching secure files from gitlab: %v", err)
+		}
+		if file.Name == targetName {
+			found = file
+		}
 	}
 
-	return files, appRepo, nil
+	return found, nil
+}
+
+func listSecureFiles(client *gitlab.Client, projectId string) paginate.Fetch[*gitlab.SecureFile] {
+	return func(page gitlab.ListOptions) ([]*gitlab.SecureFile, *gitlab.Response, error) {
+		opt := gitlab.ListProjectSecureFilesOptions(page)
+		return client.SecureFiles.ListProjectSecureFiles(projectId, &opt)
+	}
 }
 
 func Delete(client *gitlab.Client, id int, targetName string, projectId string) error {
+	if dryrun.Enabled() {
+		dryrun.Printf("delete secure file (id: %d, name: %s) from project %s", id, targetName, projectId)
+		return nil
+	}
+
 	resp, err := client.SecureFiles.RemoveSecureFile(projectId, id)
 	if err != nil {
 		return fmt.Errorf("Not able to delete the secure file (%s): %v", targetName, err)
@@ -63,6 +109,16 @@ func Create(client *gitlab.Client, path string, projectId string) error {
 	if err != nil {
 		return fmt.Errorf("Not able to open the given secrets archive (%s): %v", path, err)
 	}
+	defer file.Close()
+
+	if dryrun.Enabled() {
+		info, err := file.Stat()
+		if err != nil {
+			return fmt.Errorf("Not able to read the given secrets archive info (%s): %v", path, err)
+		}
+		dryrun.Printf("upload secure file %s (%d bytes) as .secrets.tar.gz to project %s", path, info.Size(), projectId)
+		return nil
+	}
 
 	opt := &gitlab.CreateSecureFileOptions{
 		Name: gitlab.Ptr(".secrets.tar.gz"),
@@ -84,23 +140,3 @@ func Create(client *gitlab.Client, path string, projectId string) error {
 
 	return nil
 }
-
-func GetSecureFile(files []*gitlab.SecureFile, targetFileName string) (bool, *gitlab.SecureFile) {
-	var foundFile = false
-	var secureFile *gitlab.SecureFile
-
-	for _, file := range files {
-		if file.Name != targetFileName {
-			continue
-		}
-
-		foundFile = true
-		secureFile = file
-	}
-
-	if !foundFile {
-		return false, nil
-	}
-
-	return true, secureFile
-}
@@ -0,0 +1,46 @@
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestFindByName(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/1/secure_files", func(w http.ResponseWriter, r *http.Request) {
+		files := map[string][]map[string]any{
+			"1": {{"id": 1, "name": ".secrets.tar.gz"}, {"id": 2, "name": "keystore.jks"}},
+			"2": {{"id": 3, "name": ".secrets.tar.gz"}, {"id": 4, "name": "other"}},
+		}[r.URL.Query().Get("page")]
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("X-Next-Page", "2")
+		}
+		json.NewEncoder(w).Encode(files)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(srv.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := map[string]int{".secrets.tar.gz": 3, "keystore.jks": 2, "missing": 0}
+	for name, want := range tt {
+		file, err := FindByName(client, "1", name)
+		if err != nil {
+			t.Fatalf("FindByName(%s) error = %v", name, err)
+		}
+		got := 0
+		if file != nil {
+			got = file.ID
+		}
+		if got != want {
+			t.Errorf("FindByName(%s) = file %d, want %d", name, got, want)
+		}
+	}
+}
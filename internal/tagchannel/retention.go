@@ -0,0 +1,92 @@
+package tagchannel
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reasons a tag is kept or pruned by a Policy.
+const (
+	ReasonNewest   = "newest"
+	ReasonReleased = "released"
+	ReasonRecent   = "recent"
+	ReasonExpired  = "expired"
+)
+
+// Policy selects the tags of a channel to prune. The Keep newest versions
+// are always kept, of the older ones only the tags older than OlderThan are
+// pruned, every one when OlderThan is zero. Tags of released versions are
+// never pruned.
+type Policy struct {
+	Keep      int
+	OlderThan time.Duration
+}
+
+// Decision is what the policy decided for a tag.
+type Decision struct {
+	Tag    Tag
+	Prune  bool
+	Reason string
+}
+
+// Validate returns an error when the policy is invalid or would prune every
+// tag of the channel.
+func (p Policy) Validate() error {
+	if p.Keep < 0 || p.OlderThan < 0 {
+		return errors.New("the number of tags kept and their age can not be negative")
+	}
+	if p.Keep == 0 && p.OlderThan == 0 {
+		return errors.New("the policy would prune every tag, at least the number of tags kept or their age is required")
+	}
+	return nil
+}
+
+// Apply returns the decision for every tag, in the order of the tags.
+// The tags must be sorted by version, the oldest first (see List).
+// released reports whether a tag belongs to a release that must be kept.
+func (p Policy) Apply(tags []Tag, released func(Tag) bool, now time.Time) []Decision {
+	decisions := make([]Decision, len(tags))
+	for i, tag := range tags {
+		decision := Decision{Tag: tag}
+		switch {
+		case len(tags)-i <= p.Keep:
+			decision.Reason = ReasonNewest
+		case released(tag):
+			decision.Reason = ReasonReleased
+		case p.OlderThan > 0 && (tag.Date.IsZero() || now.Sub(tag.Date) < p.OlderThan):
+			// a tag without a date is kept as its age is unknown
+			decision.Reason = ReasonRecent
+		default:
+			decision.Prune = true
+			decision.Reason = ReasonExpired
+		}
+		decisions[i] = decision
+	}
+	return decisions
+}
+
+// ParseAge parses an age like "90d", "12w" or any time.ParseDuration
+// duration, e.g. "36h".
+func ParseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	} {
+		if n, found := strings.CutSuffix(s, suffix); found {
+			count, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, fmt.Errorf("invalid age '%s', expected a number of days (90d), weeks (12w) or a duration (36h)", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	age, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age '%s', expected a number of days (90d), weeks (12w) or a duration (36h)", s)
+	}
+	return age, nil
+}
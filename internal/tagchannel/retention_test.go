@@ -0,0 +1,136 @@
+package tagchannel
+
+import (
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/version"
+)
+
+// fakeRepository lists fixed tags, the other methods are not implemented.
+type fakeRepository struct {
+	git.Repository
+	tags []git.TagInfo
+}
+
+func (f fakeRepository) ListTagInfo(pattern string) ([]git.TagInfo, error) {
+	matched := []git.TagInfo{}
+	for _, tag := range f.tags {
+		if ok, _ := path.Match(pattern, tag.Name); ok {
+			matched = append(matched, tag)
+		}
+	}
+	return matched, nil
+}
+
+func TestList(t *testing.T) {
+	repo := fakeRepository{tags: []git.TagInfo{
+		{Name: "QA-v2025.200.01+10"},
+		{Name: "QA-v2025.200.01+09"},
+		{Name: "QA-vnext"},
+		{Name: "QA-v2025.100.02+03"},
+		{Name: "PROD-v2025.200.01"},
+		{Name: "v1.0.0"},
+	}}
+	channels := []Channel{
+		{Name: "qa", Template: "QA-v{version}"},
+		{Name: "prod", Template: "PROD-v{version_no_build}"},
+	}
+
+	tags, err := List(repo, channels)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	got := []string{}
+	for _, tag := range tags {
+		got = append(got, tag.Channel+":"+tag.Name)
+	}
+	want := []string{"qa:QA-v2025.100.02+03", "prod:PROD-v2025.200.01", "qa:QA-v2025.200.01+09", "qa:QA-v2025.200.01+10"}
+	if !slices.Equal(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+}
+
+func TestPolicyApply(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	tag := func(build, ageDays int) Tag {
+		v := version.Version{Year: 2025, Major: 200, Minor: 1, Build: build}
+		return Tag{Name: "QA-v" + v.String(), Version: v, Date: now.AddDate(0, 0, -ageDays)}
+	}
+	tags := []Tag{tag(1, 200), tag(2, 150), tag(3, 120), tag(4, 30), tag(5, 100), tag(6, 1), {Name: "QA-v2025.200.01+07", Version: version.Version{Year: 2025, Major: 200, Minor: 1, Build: 7}}, tag(8, 300)}
+	released := func(t Tag) bool { return t.Version.Build == 2 }
+
+	tt := []struct {
+		name   string
+		policy Policy
+		want   []string
+	}{
+		{
+			name:   "keep and age",
+			policy: Policy{Keep: 2, OlderThan: 90 * 24 * time.Hour},
+			want:   []string{ReasonExpired, ReasonReleased, ReasonExpired, ReasonRecent, ReasonExpired, ReasonRecent, ReasonNewest, ReasonNewest},
+		},
+		{
+			name:   "keep only",
+			policy: Policy{Keep: 3},
+			want:   []string{ReasonExpired, ReasonReleased, ReasonExpired, ReasonExpired, ReasonExpired, ReasonNewest, ReasonNewest, ReasonNewest},
+		},
+		{
+			name:   "age only",
+			policy: Policy{OlderThan: 110 * 24 * time.Hour},
+			want:   []string{ReasonExpired, ReasonReleased, ReasonExpired, ReasonRecent, ReasonRecent, ReasonRecent, ReasonRecent, ReasonExpired},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got := []string{}
+			for _, decision := range tc.policy.Apply(tags, released, now) {
+				if decision.Prune != (decision.Reason == ReasonExpired) {
+					t.Errorf("%s: Prune = %t with reason %s", decision.Tag.Name, decision.Prune, decision.Reason)
+				}
+				got = append(got, decision.Reason)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Apply() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	if err := (Policy{}).Validate(); err == nil {
+		t.Error("Validate() of an empty policy error = nil, want error")
+	}
+	if err := (Policy{Keep: -1, OlderThan: time.Hour}).Validate(); err == nil {
+		t.Error("Validate() of a negative keep error = nil, want error")
+	}
+	if err := (Policy{Keep: 50}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tt := map[string]time.Duration{
+		"90d": 90 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+		"0d":  0,
+	}
+	for s, want := range tt {
+		got, err := ParseAge(s)
+		if err != nil || got != want {
+			t.Errorf("ParseAge(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "d", "ninety days", "1.5d"} {
+		if _, err := ParseAge(s); err == nil {
+			t.Errorf("ParseAge(%q) error = nil, want error", s)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package tagchannel names the release tags of a channel (QA, production,
+// hotfix...) after the version, e.g. "QA-v2025.200.01+04", and reads the
+// version back from the tag names.
+package tagchannel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ulist.app/ult/internal/config"
+	"ulist.app/ult/internal/version"
+)
+
+// ConfigKey is the .ult.yaml key holding the list of tag channels.
+const ConfigKey = "tag-channels"
+
+// QA is the channel of the QA builds, its tags are read by
+// 'ult release check' and 'ult release set-version --from-tag'.
+const QA = "qa"
+
+// Channel is a kind of release tag. Template is the tag name with the
+// version placeholders (see version.Expand), it must contain {version} or
+// {version_no_build}.
+type Channel struct {
+	Name     string `json:"name" yaml:"name"`
+	Template string `json:"template" yaml:"template"`
+}
+
+// DefaultChannels returns the channels known without configuration, the
+// QA tags ult always created.
+func DefaultChannels() []Channel {
+	return []Channel{
+		{Name: QA, Template: "QA-v" + version.PlaceholderVersion},
+	}
+}
+
+// Load reads the channels from the configuration. The configured channels
+// are added to the defaults, a configured channel with the name of a
+// default one replaces it.
+func Load(cfg *config.Config) ([]Channel, error) {
+	configured := []Channel{}
+	if _, err := cfg.Decode(ConfigKey, &configured); err != nil {
+		return nil, err
+	}
+
+	channels := DefaultChannels()
+	for _, channel := range configured {
+		if err := channel.validate(); err != nil {
+			return nil, err
+		}
+		replaced := false
+		for i := range channels {
+			if strings.EqualFold(channels[i].Name, channel.Name) {
+				channels[i] = channel
+				replaced = true
+			}
+		}
+		if !replaced {
+			channels = append(channels, channel)
+		}
+	}
+	return channels, nil
+}
+
+// Find returns the channel with the name, names are case insensitive.
+func Find(channels []Channel, name string) (*Channel, error) {
+	names := make([]string, 0, len(channels))
+	for i := range channels {
+		if strings.EqualFold(channels[i].Name, name) {
+			return &channels[i], nil
+		}
+		names = append(names, channels[i].Name)
+	}
+	return nil, fmt.Errorf("unknown tag channel '%s' (configured channels: %s)", name, strings.Join(names, ", "))
+}
+
+func (c Channel) validate() error {
+	if len(c.Name) == 0 {
+		return fmt.Errorf("invalid %s, every channel requires a name", ConfigKey)
+	}
+	if !strings.Contains(c.Template, version.PlaceholderVersion) && !strings.Contains(c.Template, version.PlaceholderVersionNoBuild) {
+		return fmt.Errorf("invalid template '%s' of tag channel %s, it must contain %s or %s",
+			c.Template, c.Name, version.PlaceholderVersion, version.PlaceholderVersionNoBuild)
+	}
+	if _, err := c.regexp(); err != nil {
+		return fmt.Errorf("invalid template '%s' of tag channel %s: %w", c.Template, c.Name, err)
+	}
+	return nil
+}
+
+// TagName returns the name of the version's tag in the channel.
+func (c Channel) TagName(v version.Version) string {
+	return v.Expand(c.Template)
+}
+
+// Pattern returns the glob pattern matching the tags of the channel, e.g.
+// "QA-v*".
+func (c Channel) Pattern() string {
+	return placeholders.ReplaceAllString(c.Template, "*")
+}
+
+// HasBuild reports whether the tag names hold the build number.
+func (c Channel) HasBuild() bool {
+	return strings.Contains(c.Template, version.PlaceholderVersion) || strings.Contains(c.Template, version.PlaceholderBuild)
+}
+
+// Parse reads the version from a tag name of the channel. The build number
+// of channels without one (see HasBuild) is 0.
+func (c Channel) Parse(tag string) (*version.Version, error) {
+	re, err := c.regexp()
+	if err != nil {
+		return nil, err
+	}
+	matches := re.FindStringSubmatch(tag)
+	if matches == nil {
+		return nil, fmt.Errorf("tag %s does not match the template '%s' of channel %s", tag, c.Template, c.Name)
+	}
+
+	groups := map[string]string{}
+	for i, name := range re.SubexpNames() {
+		if len(name) > 0 && len(matches[i]) > 0 {
+			groups[name] = matches[i]
+		}
+	}
+
+	versionStr, found := groups["version"]
+	if !found {
+		build, found := groups["build"]
+		if !found {
+			build = "0"
+		}
+		versionStr = groups["version_no_build"] + "+" + build
+	}
+
+	v, err := version.Parse(versionStr)
+	if err != nil {
+		return nil, fmt.Errorf("tag %s of channel %s: %w", tag, c.Name, err)
+	}
+	return v, nil
+}
+
+// placeholders matches the placeholders of the templates.
+var placeholders = regexp.MustCompile(`\{(version|version_no_build|build|pre_release)\}`)
+
+// placeholderPatterns are the expressions the placeholders match in a tag
+// name, tags can not contain spaces.
+var placeholderPatterns = map[string]string{
+	"version":          `\S+?`,
+	"version_no_build": `[^\s+]+?`,
+	"build":            `\d+`,
+	"pre_release":      `[0-9A-Za-z.-]*?`,
+}
+
+// regexp returns the expression matching the tag names of the channel, each
+// placeholder is a named group.
+func (c Channel) regexp() (*regexp.Regexp, error) {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	seen := map[string]bool{}
+	last := 0
+	for _, loc := range placeholders.FindAllStringSubmatchIndex(c.Template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(c.Template[last:loc[0]]))
+		name := c.Template[loc[2]:loc[3]]
+		if seen[name] {
+			// the value of a repeated placeholder is read once
+			fmt.Fprintf(&pattern, "(?:%s)", placeholderPatterns[name])
+		} else {
+			fmt.Fprintf(&pattern, "(?P<%s>%s)", name, placeholderPatterns[name])
+		}
+		seen[name] = true
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(c.Template[last:]))
+	pattern.WriteString("$")
+
+	return regexp.Compile(pattern.String())
+}
@@ -0,0 +1,141 @@
+package tagchannel
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ulist.app/ult/internal/config"
+	"ulist.app/ult/internal/version"
+)
+
+func TestTagName(t *testing.T) {
+	v := version.Version{Year: 2025, Major: 200, Minor: 1, Build: 4}
+
+	tt := map[string]string{
+		"QA-v{version}":                     "QA-v2025.200.01+04",
+		"PROD-v{version_no_build}":          "PROD-v2025.200.01",
+		"hotfix/{version_no_build}-{build}": "hotfix/2025.200.01-4",
+	}
+
+	for template, want := range tt {
+		if got := (Channel{Name: "test", Template: template}).TagName(v); got != want {
+			t.Errorf("TagName() of %q = %q, want %q", template, got, want)
+		}
+	}
+}
+
+func TestPattern(t *testing.T) {
+	tt := map[string]string{
+		"QA-v{version}":                     "QA-v*",
+		"PROD-v{version_no_build}":          "PROD-v*",
+		"hotfix/{version_no_build}-{build}": "hotfix/*-*",
+	}
+
+	for template, want := range tt {
+		if got := (Channel{Name: "test", Template: template}).Pattern(); got != want {
+			t.Errorf("Pattern() of %q = %q, want %q", template, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		template string
+		tag      string
+		want     string
+		wantErr  bool
+	}{
+		{template: "QA-v{version}", tag: "QA-v2025.200.01+04", want: "2025.200.01+04"},
+		{template: "QA-v{version}", tag: "QA-v2025.200.01-rc.1+04", want: "2025.200.01-rc.1+04"},
+		{template: "QA-v{version}", tag: "QA-v2025.200.01", wantErr: true},
+		{template: "QA-v{version}", tag: "PROD-v2025.200.01+04", wantErr: true},
+		{template: "QA-v{version}", tag: "xQA-v2025.200.01+04", wantErr: true},
+		{template: "PROD-v{version_no_build}", tag: "PROD-v2025.200.01", want: "2025.200.01+00"},
+		{template: "PROD-v{version_no_build}", tag: "PROD-v2025.200.01+04", wantErr: true},
+		{template: "hotfix/{version_no_build}-{build}", tag: "hotfix/2025.200.01-4", want: "2025.200.01+04"},
+		{template: "hotfix/{version_no_build}-{build}", tag: "hotfix/2025.200.01-rc.2-4", want: "2025.200.01-rc.2+04"},
+		{template: "v{version}.{version_no_build}", tag: "v2025.200.01+04.2025.200.01", want: "2025.200.01+04"},
+		{template: "rel-{version}-qa", tag: "rel-2025.200.01+04-qa", want: "2025.200.01+04"},
+	}
+
+	for _, tc := range tt {
+		got, err := (Channel{Name: "test", Template: tc.template}).Parse(tc.tag)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) with %q = %s, want error", tc.tag, tc.template, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) with %q error = %v", tc.tag, tc.template, err)
+			continue
+		}
+		if got.String() != tc.want {
+			t.Errorf("Parse(%q) with %q = %s, want %s", tc.tag, tc.template, got, tc.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), config.ProjectFileName)
+	contents := `tag-channels:
+  - name: prod
+    template: PROD-v{version_no_build}
+  - name: QA
+    template: qa/{version}
+`
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadFrom(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	channels, err := Load(cfg)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []Channel{{Name: "QA", Template: "qa/{version}"}, {Name: "prod", Template: "PROD-v{version_no_build}"}}
+	if len(channels) != len(want) {
+		t.Fatalf("Load() = %v, want %v", channels, want)
+	}
+	for i := range want {
+		if channels[i] != want[i] {
+			t.Errorf("Load()[%d] = %v, want %v", i, channels[i], want[i])
+		}
+	}
+
+	channel, err := Find(channels, "Prod")
+	if err != nil || channel.Name != "prod" {
+		t.Errorf("Find(prod) = %v, %v", channel, err)
+	}
+	if _, err := Find(channels, "hotfix"); err == nil {
+		t.Error("Find(hotfix) error = nil, want error")
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	channels, err := Load(&config.Config{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(channels) != 1 || channels[0] != DefaultChannels()[0] {
+		t.Errorf("Load() = %v, want the default channels", channels)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := []Channel{
+		{Name: "", Template: "v{version}"},
+		{Name: "prod", Template: "PROD"},
+		{Name: "prod", Template: "PROD-{build}"},
+	}
+
+	for _, channel := range invalid {
+		if err := channel.validate(); err == nil {
+			t.Errorf("validate() of %v error = nil, want error", channel)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package tagchannel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/version"
+)
+
+var (
+	logger = logging.New("tagchannel")
+)
+
+// Tag is a release tag and the version read from its name.
+type Tag struct {
+	Name    string
+	Channel string
+	Version version.Version
+	Commit  string
+	Date    time.Time
+}
+
+// List returns the tags of the channels sorted by version, the oldest
+// first. A tag matching several channels belongs to the first one, tags
+// whose version can not be read are skipped.
+func List(repo git.Repository, channels []Channel) ([]Tag, error) {
+	tags := []Tag{}
+	seen := map[string]bool{}
+	for _, channel := range channels {
+		infos, err := repo.ListTagInfo(channel.Pattern())
+		if err != nil {
+			return nil, fmt.Errorf("listing tags of channel %s: %w", channel.Name, err)
+		}
+
+		for _, info := range infos {
+			if seen[info.Name] {
+				continue
+			}
+			v, err := channel.Parse(info.Name)
+			if err != nil {
+				logger.Debug("skipping tag with an invalid version", "tag", info.Name, "channel", channel.Name, "error", err)
+				continue
+			}
+			seen[info.Name] = true
+			tags = append(tags, Tag{Name: info.Name, Channel: channel.Name, Version: *v, Commit: info.Commit, Date: info.Date})
+		}
+	}
+
+	slices.SortStableFunc(tags, func(a, b Tag) int {
+		if c := version.Compare(a.Version, b.Version); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return tags, nil
+}
@@ -0,0 +1,112 @@
+// Package tagmessage renders the message of annotated release tags from a
+// template, e.g. "Release {version}\n\n{changelog}\n\n{pipeline_url}".
+package tagmessage
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/version"
+)
+
+var (
+	logger = logging.New("tagmessage")
+)
+
+// Placeholders supported by Render besides the version placeholders.
+const (
+	PlaceholderTag         = "{tag}"
+	PlaceholderChangelog   = "{changelog}"
+	PlaceholderPipelineURL = "{pipeline_url}"
+)
+
+// DefaultTemplate is the message of signed tags when no template is set.
+const DefaultTemplate = "Release " + PlaceholderTag
+
+// Data is what the placeholders are replaced with.
+type Data struct {
+	Tag string
+	// Version is nil when the version of the tag is unknown
+	Version *version.Version
+	// Commits are the commits since the previous tag, newest first
+	Commits     []git.Commit
+	PipelineURL string
+}
+
+var blankLinesRegex = regexp.MustCompile(`\n{3,}`)
+
+// Render returns the message of the template, placeholders without a value
+// are removed together with the blank lines they leave.
+func Render(template string, data Data) string {
+	message := template
+	if data.Version != nil {
+		message = data.Version.Expand(message)
+	}
+	message = strings.NewReplacer(
+		PlaceholderTag, data.Tag,
+		PlaceholderChangelog, Changelog(data.Commits),
+		PlaceholderPipelineURL, data.PipelineURL,
+	).Replace(message)
+
+	return strings.TrimSpace(blankLinesRegex.ReplaceAllString(message, "\n\n"))
+}
+
+// Changelog returns a line per commit with its subject and short hash.
+func Changelog(commits []git.Commit) string {
+	lines := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		hash := commit.Hash
+		if len(hash) > 8 {
+			hash = hash[:8]
+		}
+		lines = append(lines, fmt.Sprintf("- %s (%s)", commit.Subject, hash))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Build renders the template of the tag at ref, the template of signed tags
+// defaults to DefaultTemplate. The changelog is only listed when the
+// template uses it and ref is in the local clone, otherwise it stays empty.
+func Build(template string, data Data, ref string, local bool) string {
+	if len(template) == 0 && git.SigningEnabled() {
+		template = DefaultTemplate
+	}
+	if len(template) == 0 {
+		return ""
+	}
+
+	if NeedsChangelog(template) && data.Commits == nil {
+		if !local {
+			logger.Warn("The changelog of the tag message requires the git backend", "tag", data.Tag)
+		} else if commits, err := CommitsSinceLastTag(ref); err != nil {
+			logger.Warn("Not able to list the commits since the previous tag", "tag", data.Tag, "error", err)
+		} else {
+			data.Commits = commits
+		}
+	}
+	return Render(template, data)
+}
+
+// NeedsChangelog reports whether the template uses the changelog, so the
+// commits are only listed when needed.
+func NeedsChangelog(template string) bool {
+	return strings.Contains(template, PlaceholderChangelog)
+}
+
+// CommitsSinceLastTag returns the commits reachable from ref since the
+// previous tag, every commit when there is none. Only the local clone knows
+// which tag is the previous one.
+func CommitsSinceLastTag(ref string) ([]git.Commit, error) {
+	if len(ref) == 0 {
+		ref = "HEAD"
+	}
+
+	previous, err := git.GetLatestTag(ref+"^", "")
+	if err != nil {
+		return nil, err
+	}
+	return git.GetCommitRange(previous, ref)
+}
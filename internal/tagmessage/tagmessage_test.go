@@ -0,0 +1,56 @@
+package tagmessage
+
+import (
+	"testing"
+
+	"ulist.app/ult/internal/git"
+	"ulist.app/ult/internal/version"
+)
+
+func TestRender(t *testing.T) {
+	v := version.Version{Year: 2025, Major: 200, Minor: 1, Build: 4, Scheme: version.UList}
+	commits := []git.Commit{
+		{Hash: "3a4f5d6e7f8a9b0c", Subject: "bump version [skip ci]"},
+		{Hash: "a1b2c3d", Subject: "fix: crash on start"},
+	}
+
+	tt := []struct {
+		name     string
+		template string
+		data     Data
+		want     string
+	}{
+		{
+			name:     "version",
+			template: "Release {version} ({build})",
+			data:     Data{Tag: "v1", Version: &v},
+			want:     "Release 2025.200.01+04 (4)",
+		},
+		{
+			name:     "changelog and pipeline",
+			template: "Release {tag}\n\n{changelog}\n\nPipeline: {pipeline_url}",
+			data:     Data{Tag: "QA-v2025.200.01+04", Commits: commits, PipelineURL: "https://gitlab.com/p/-/pipelines/1"},
+			want:     "Release QA-v2025.200.01+04\n\n- bump version [skip ci] (3a4f5d6e)\n- fix: crash on start (a1b2c3d)\n\nPipeline: https://gitlab.com/p/-/pipelines/1",
+		},
+		{
+			name:     "empty placeholders",
+			template: "Release {tag}\n\n{changelog}\n\n{pipeline_url}\n",
+			data:     Data{Tag: "v1"},
+			want:     "Release v1",
+		},
+		{
+			name:     "unknown version",
+			template: "Release {version}",
+			data:     Data{Tag: "v1"},
+			want:     "Release {version}",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Render(tc.template, tc.data); got != tc.want {
+				t.Errorf("Render() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
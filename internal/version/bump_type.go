@@ -13,6 +13,9 @@ const (
 	BumpTypeMilestone
 	BumpTypeMinor
 	BumpTypeBuild
+	BumpTypePatch
+	BumpTypePreRelease
+	BumpTypePromote
 )
 
 func (b BumpType) String() string {
@@ -27,6 +30,12 @@ func (b BumpType) String() string {
 		return "minor"
 	case BumpTypeBuild:
 		return "build"
+	case BumpTypePatch:
+		return "patch"
+	case BumpTypePreRelease:
+		return "prerelease"
+	case BumpTypePromote:
+		return "promote"
 	}
 
 	msg := fmt.Sprintf("invalid build type: %T", b)
@@ -45,6 +54,12 @@ func ParseBumpType(s string) (BumpType, error) {
 		return BumpTypeMinor, nil
 	case "build":
 		return BumpTypeBuild, nil
+	case "patch":
+		return BumpTypePatch, nil
+	case "prerelease":
+		return BumpTypePreRelease, nil
+	case "promote":
+		return BumpTypePromote, nil
 	}
 
 	return BumpTypeYear, fmt.Errorf("invalid bump type: %s", s)
@@ -0,0 +1,89 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// CalVer is the calendar versioning scheme "YY.MM.patch+build", e.g. "25.04.2+31".
+// The components are stored as Year (YY), Major (MM) and Minor (patch), the
+// build number is optional.
+var CalVer Scheme = calVerScheme{}
+
+// now returns the time calver bumps move the version to, replaced by tests.
+var now = time.Now
+
+var calVerRegex = regexp.MustCompile(`\b(\d\d)\.(\d\d?)\.(\d+)` + preReleasePattern + `(?:\+(\d+))?`)
+
+type calVerScheme struct{}
+
+func (calVerScheme) Name() string {
+	return "calver"
+}
+
+func (s calVerScheme) Parse(line string) (*Version, error) {
+	if len(line) == 0 {
+		return nil, errors.New("Version line string cannot be empty")
+	}
+	matches := calVerRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, fmt.Errorf("Version string doesn't match expected format \"version: 25.04.1+01\", got: %s", line)
+	}
+
+	numbers, err := atoiAll([]string{matches[1], matches[2], matches[3], matches[5]})
+	if err != nil {
+		return nil, err
+	}
+	if numbers[1] < 1 || numbers[1] > 12 {
+		return nil, fmt.Errorf("invalid calver month (%d) in: %s", numbers[1], line)
+	}
+
+	return &Version{
+		Year:       numbers[0],
+		Major:      numbers[1],
+		Minor:      numbers[2],
+		PreRelease: matches[4],
+		Build:      numbers[3],
+		Scheme:     s,
+	}, nil
+}
+
+func (calVerScheme) Format(v Version) string {
+	return withBuild(withPreRelease(fmt.Sprintf("%02d.%02d.%d", v.Year, v.Major, v.Minor), v.PreRelease), v.Build)
+}
+
+// Bump moves the version to the current month for year, milestone and major
+// bumps (restarting the patch at 0) and increments the patch for minor and
+// patch bumps. Any bump other than build resets the build number to 1.
+func (s calVerScheme) Bump(v *Version, bumpType BumpType) error {
+	switch bumpType {
+	case BumpTypeBuild:
+		v.Build++
+		return nil
+
+	case BumpTypeMinor, BumpTypePatch:
+		v.Minor++
+
+	case BumpTypeYear, BumpTypeMilestone, BumpTypeMajor:
+		today := now()
+		year, month := today.Year()%100, int(today.Month())
+		if year == v.Year && month == v.Major {
+			return fmt.Errorf("version %s is already in the current calver period", s.Format(*v))
+		}
+		v.Year = year
+		v.Major = month
+		v.Minor = 0
+
+	default:
+		return fmt.Errorf("bump type %s is not supported by the calver version scheme", bumpType)
+	}
+
+	v.Build = 1
+	return nil
+}
+
+func (calVerScheme) Compare(a, b Version) int {
+	return compareComponents(a, b)
+}
@@ -0,0 +1,87 @@
+package version
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Compare returns -1 if a is lower than b, 0 if they are equal and +1 if a
+// is greater than b, according to the scheme of a.
+func Compare(a, b Version) int {
+	return a.scheme().Compare(a, b)
+}
+
+// Compare returns -1 if v is lower than other, 0 if they are equal and +1
+// if v is greater than other.
+func (v Version) Compare(other Version) int {
+	return Compare(v, other)
+}
+
+// Less reports whether v is lower than other.
+func (v Version) Less(other Version) bool {
+	return Compare(v, other) < 0
+}
+
+// Equal reports whether v and other have the same precedence, including
+// the pre-release and the build number.
+func (v Version) Equal(other Version) bool {
+	return Compare(v, other) == 0
+}
+
+// Sort sorts the versions in ascending order, versions with the same
+// precedence keep their original order.
+func Sort(versions []Version) {
+	slices.SortStableFunc(versions, Compare)
+}
+
+// Max returns the greatest of the given versions, nil if there are none.
+func Max(versions ...Version) *Version {
+	if len(versions) == 0 {
+		return nil
+	}
+
+	max := versions[0]
+	for _, v := range versions[1:] {
+		if Compare(v, max) > 0 {
+			max = v
+		}
+	}
+	return &max
+}
+
+// Relation is a comparison operator used to check the result of Compare.
+type Relation string
+
+const (
+	RelationLess         Relation = "lt"
+	RelationLessEqual    Relation = "le"
+	RelationEqual        Relation = "eq"
+	RelationGreaterEqual Relation = "ge"
+	RelationGreater      Relation = "gt"
+)
+
+// ParseRelation converts a string like "gt" into a Relation.
+func ParseRelation(s string) (Relation, error) {
+	switch r := Relation(s); r {
+	case RelationLess, RelationLessEqual, RelationEqual, RelationGreaterEqual, RelationGreater:
+		return r, nil
+	}
+	return "", fmt.Errorf("invalid relation: %s (valid relations: lt, le, eq, ge, gt)", s)
+}
+
+// Holds reports whether the result of Compare satisfies the relation.
+func (r Relation) Holds(cmp int) bool {
+	switch r {
+	case RelationLess:
+		return cmp < 0
+	case RelationLessEqual:
+		return cmp <= 0
+	case RelationEqual:
+		return cmp == 0
+	case RelationGreaterEqual:
+		return cmp >= 0
+	case RelationGreater:
+		return cmp > 0
+	}
+	return false
+}
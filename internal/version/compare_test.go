@@ -0,0 +1,127 @@
+package version
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tt := []struct {
+		name string
+		a    Version
+		b    Version
+		want int
+	}{
+		{
+			name: "equal",
+			a:    Version{Year: 2025, Major: 200, Minor: 1, Build: 4},
+			b:    Version{Year: 2025, Major: 200, Minor: 1, Build: 4},
+			want: 0,
+		},
+		{
+			name: "lower year",
+			a:    Version{Year: 2024, Major: 900, Minor: 9, Build: 99},
+			b:    Version{Year: 2025, Major: 100, Minor: 0, Build: 1},
+			want: -1,
+		},
+		{
+			name: "greater minor",
+			a:    Version{Year: 2025, Major: 200, Minor: 2, Build: 1},
+			b:    Version{Year: 2025, Major: 200, Minor: 1, Build: 9},
+			want: 1,
+		},
+		{
+			name: "lower build",
+			a:    Version{Year: 2025, Major: 200, Minor: 1, Build: 3},
+			b:    Version{Year: 2025, Major: 200, Minor: 1, Build: 4},
+			want: -1,
+		},
+		{
+			name: "pre-release before release",
+			a:    Version{Year: 2025, Major: 200, Minor: 1, PreRelease: "rc.2", Build: 9},
+			b:    Version{Year: 2025, Major: 200, Minor: 1, Build: 1},
+			want: -1,
+		},
+		{
+			name: "semver scheme",
+			a:    Version{Year: 1, Major: 10, Minor: 0, Scheme: SemVer},
+			b:    Version{Year: 1, Major: 9, Minor: 3, Scheme: SemVer},
+			want: 1,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Compare(tc.a, tc.b); got != tc.want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", tc.a, tc.b, got, tc.want)
+			}
+			if got := Compare(tc.b, tc.a); got != -tc.want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", tc.b, tc.a, got, -tc.want)
+			}
+			if got := tc.a.Less(tc.b); got != (tc.want < 0) {
+				t.Errorf("%s.Less(%s) = %t", tc.a, tc.b, got)
+			}
+			if got := tc.a.Equal(tc.b); got != (tc.want == 0) {
+				t.Errorf("%s.Equal(%s) = %t", tc.a, tc.b, got)
+			}
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	versions := []Version{
+		{Year: 2025, Major: 200, Minor: 1, Build: 5},
+		{Year: 2024, Major: 900, Minor: 3, Build: 40},
+		{Year: 2025, Major: 200, Minor: 1, PreRelease: "rc.10", Build: 4},
+		{Year: 2025, Major: 200, Minor: 1, PreRelease: "rc.2", Build: 3},
+		{Year: 2025, Major: 100, Minor: 12, Build: 1},
+	}
+	want := []string{
+		"2024.900.03+40",
+		"2025.100.12+01",
+		"2025.200.01-rc.2+03",
+		"2025.200.01-rc.10+04",
+		"2025.200.01+05",
+	}
+
+	Sort(versions)
+
+	for i, v := range versions {
+		if v.String() != want[i] {
+			t.Errorf("Sort()[%d] = %s, want %s", i, v, want[i])
+		}
+	}
+
+	if got := Max(versions...); got == nil || got.String() != want[len(want)-1] {
+		t.Errorf("Max() = %v, want %s", got, want[len(want)-1])
+	}
+	if got := Max(); got != nil {
+		t.Errorf("Max() = %v, want nil", got)
+	}
+}
+
+func TestRelationHolds(t *testing.T) {
+	tt := []struct {
+		input string
+		cmp   int
+		want  bool
+	}{
+		{input: "gt", cmp: 1, want: true},
+		{input: "gt", cmp: 0, want: false},
+		{input: "ge", cmp: 0, want: true},
+		{input: "eq", cmp: 0, want: true},
+		{input: "le", cmp: 1, want: false},
+		{input: "lt", cmp: -1, want: true},
+	}
+
+	for _, tc := range tt {
+		relation, err := ParseRelation(tc.input)
+		if err != nil {
+			t.Fatalf("ParseRelation(%s) error = %v", tc.input, err)
+		}
+		if got := relation.Holds(tc.cmp); got != tc.want {
+			t.Errorf("%s.Holds(%d) = %t, want %t", relation, tc.cmp, got, tc.want)
+		}
+	}
+
+	if _, err := ParseRelation(">"); err == nil {
+		t.Error("ParseRelation(>) expected error")
+	}
+}
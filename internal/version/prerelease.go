@@ -0,0 +1,137 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// preReleasePattern matches a pre-release like "rc.2" or "beta", it is used
+// by every scheme between the version numbers and the build number.
+const preReleasePattern = `(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?`
+
+var channelRegex = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+// PreReleaseChannel returns the channel of the pre-release, e.g. "rc" for
+// "rc.2". Returns an empty string for releases.
+func (v Version) PreReleaseChannel() string {
+	channel, _, _ := strings.Cut(v.PreRelease, ".")
+	return channel
+}
+
+// IsPreRelease reports whether the version has a pre-release component.
+func (v Version) IsPreRelease() bool {
+	return len(v.PreRelease) > 0
+}
+
+// BumpInChannel bumps the version and makes it a pre-release of the given
+// channel. An empty channel behaves like Bump.
+//
+// Bumping a pre-release in the same channel increments its number, moving to
+// another channel restarts it at 1 (e.g. "beta.3" -> "rc.1"). Any other bump
+// type bumps the version numbers and starts the channel at 1.
+func (v *Version) BumpInChannel(bumpType BumpType, channel string) error {
+	if len(channel) == 0 {
+		return v.Bump(bumpType)
+	}
+	if !channelRegex.MatchString(channel) {
+		return fmt.Errorf("invalid pre-release channel: %s (only letters, digits and '-' are allowed)", channel)
+	}
+
+	switch bumpType {
+	case BumpTypePromote:
+		return fmt.Errorf("bump type %s cannot be used with a pre-release channel", bumpType)
+
+	case BumpTypePreRelease:
+		if v.PreReleaseChannel() == channel {
+			return v.Bump(bumpType)
+		}
+		v.Build++
+
+	default:
+		if err := v.Bump(bumpType); err != nil {
+			return err
+		}
+	}
+
+	v.PreRelease = channel + ".1"
+	return nil
+}
+
+// bumpPreRelease increments the last numeric identifier of the pre-release
+// ("rc.2" -> "rc.3", "rc" -> "rc.1") together with the build number.
+func (v *Version) bumpPreRelease() error {
+	if !v.IsPreRelease() {
+		return fmt.Errorf("version %s is not a pre-release, pass a pre-release channel to start one", v)
+	}
+
+	identifiers := strings.Split(v.PreRelease, ".")
+	last := len(identifiers) - 1
+	if n, err := strconv.Atoi(identifiers[last]); err == nil {
+		identifiers[last] = strconv.Itoa(n + 1)
+	} else {
+		identifiers = append(identifiers, "1")
+	}
+
+	v.PreRelease = strings.Join(identifiers, ".")
+	v.Build++
+	return nil
+}
+
+// comparePreRelease orders pre-releases following the semantic versioning
+// rules: a release is greater than any of its pre-releases, identifiers are
+// compared one by one, numeric identifiers numerically and lower than
+// alphanumeric ones, which are compared lexically.
+func comparePreRelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, aErr := strconv.Atoi(aIDs[i])
+		bNum, bErr := strconv.Atoi(bIDs[i])
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				return compareInts(aNum, bNum)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(aIDs[i], bIDs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return compareInts(len(aIDs), len(bIDs))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// withPreRelease appends the pre-release when it is set.
+func withPreRelease(s string, preRelease string) string {
+	if len(preRelease) == 0 {
+		return s
+	}
+	return s + "-" + preRelease
+}
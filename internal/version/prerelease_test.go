@@ -0,0 +1,124 @@
+package version
+
+import "testing"
+
+func TestBumpPreRelease(t *testing.T) {
+	tt := []struct {
+		name     string
+		version  Version
+		bumpType BumpType
+		channel  string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "increment pre-release",
+			version:  Version{Year: 2025, Major: 200, Minor: 1, PreRelease: "rc.2", Build: 5},
+			bumpType: BumpTypePreRelease,
+			want:     "2025.200.01-rc.3+06",
+		},
+		{
+			name:     "increment pre-release without number",
+			version:  Version{Year: 2025, Major: 200, Minor: 1, PreRelease: "beta", Build: 5},
+			bumpType: BumpTypePreRelease,
+			want:     "2025.200.01-beta.1+06",
+		},
+		{
+			name:     "pre-release of a release",
+			version:  Version{Year: 2025, Major: 200, Minor: 1, Build: 5},
+			bumpType: BumpTypePreRelease,
+			wantErr:  true,
+		},
+		{
+			name:     "promote",
+			version:  Version{Year: 2025, Major: 200, Minor: 1, PreRelease: "rc.2", Build: 5},
+			bumpType: BumpTypePromote,
+			want:     "2025.200.01+05",
+		},
+		{
+			name:     "promote a release",
+			version:  Version{Year: 2025, Major: 200, Minor: 1, Build: 5},
+			bumpType: BumpTypePromote,
+			wantErr:  true,
+		},
+		{
+			name:     "minor drops pre-release",
+			version:  Version{Year: 2025, Major: 200, Minor: 1, PreRelease: "rc.2", Build: 5},
+			bumpType: BumpTypeMinor,
+			want:     "2025.200.02+01",
+		},
+		{
+			name:     "build keeps pre-release",
+			version:  Version{Year: 2025, Major: 200, Minor: 1, PreRelease: "rc.2", Build: 5},
+			bumpType: BumpTypeBuild,
+			want:     "2025.200.01-rc.2+06",
+		},
+		{
+			name:     "start channel on minor",
+			version:  Version{Year: 2025, Major: 200, Minor: 1, Build: 5},
+			bumpType: BumpTypeMinor,
+			channel:  "rc",
+			want:     "2025.200.02-rc.1+01",
+		},
+		{
+			name:     "same channel increments",
+			version:  Version{Year: 2025, Major: 200, Minor: 1, PreRelease: "rc.1", Build: 5},
+			bumpType: BumpTypePreRelease,
+			channel:  "rc",
+			want:     "2025.200.01-rc.2+06",
+		},
+		{
+			name:     "switch channel restarts",
+			version:  Version{Year: 2025, Major: 200, Minor: 1, PreRelease: "beta.3", Build: 5},
+			bumpType: BumpTypePreRelease,
+			channel:  "rc",
+			want:     "2025.200.01-rc.1+06",
+		},
+		{
+			name:     "invalid channel",
+			version:  Version{Year: 2025, Major: 200, Minor: 1, Build: 5},
+			bumpType: BumpTypeMinor,
+			channel:  "rc.1",
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v := tc.version
+			v.Scheme = UList
+			err := v.BumpInChannel(tc.bumpType, tc.channel)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("BumpInChannel() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && v.String() != tc.want {
+				t.Errorf("BumpInChannel() = %s, want %s", v.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestComparePreRelease(t *testing.T) {
+	tt := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "rc.1", b: "", want: -1},
+		{a: "", b: "rc.1", want: 1},
+		{a: "beta.2", b: "rc.1", want: -1},
+		{a: "qa.1", b: "rc.1", want: -1},
+		{a: "rc.2", b: "rc.10", want: -1},
+		{a: "rc", b: "rc.1", want: -1},
+		{a: "rc.1", b: "rc.alpha", want: -1},
+		{a: "rc.3", b: "rc.3", want: 0},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.a+"_"+tc.b, func(t *testing.T) {
+			if got := comparePreRelease(tc.a, tc.b); got != tc.want {
+				t.Errorf("comparePreRelease(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scheme is a versioning scheme, it knows how versions are parsed,
+// formatted, bumped and compared. Every scheme stores its three numeric
+// components positionally in Version.Year, Version.Major and Version.Minor.
+type Scheme interface {
+	// Name is the identifier used to select the scheme in configuration.
+	Name() string
+	// Parse finds a version in the given line, e.g. "version: 1.2.3+4".
+	Parse(line string) (*Version, error)
+	// Format returns the string representation of the version.
+	Format(v Version) string
+	// Bump increments the version according to the bump type. Returns an
+	// error if the bump type is not supported by the scheme.
+	Bump(v *Version, bumpType BumpType) error
+	// Compare returns -1 if a is lower than b, 0 if they are equal and +1
+	// if a is greater than b.
+	Compare(a, b Version) int
+}
+
+var (
+	schemes = []Scheme{
+		UList,
+		SemVer,
+		CalVer,
+	}
+
+	defaultScheme = UList
+)
+
+// SchemeByName returns the scheme registered with the given name, an empty
+// name returns the uList scheme.
+func SchemeByName(name string) (Scheme, error) {
+	if len(name) == 0 {
+		return UList, nil
+	}
+
+	names := make([]string, 0, len(schemes))
+	for _, scheme := range schemes {
+		if strings.EqualFold(scheme.Name(), name) {
+			return scheme, nil
+		}
+		names = append(names, scheme.Name())
+	}
+
+	return nil, fmt.Errorf("invalid version scheme: %s (valid schemes: %s)", name, strings.Join(names, ", "))
+}
+
+// Default returns the scheme used by Parse, FetchFromLines and by versions
+// that were not created through a scheme.
+func Default() Scheme {
+	return defaultScheme
+}
+
+// SetDefault selects the scheme of the current project.
+func SetDefault(scheme Scheme) {
+	defaultScheme = scheme
+}
+
+// compareComponents compares two versions by their numbers, then by their
+// pre-release and lastly by their build number.
+func compareComponents(a, b Version) int {
+	for _, pair := range [][2]int{
+		{a.Year, b.Year},
+		{a.Major, b.Major},
+		{a.Minor, b.Minor},
+	} {
+		if c := compareInts(pair[0], pair[1]); c != 0 {
+			return c
+		}
+	}
+
+	if c := comparePreRelease(a.PreRelease, b.PreRelease); c != 0 {
+		return c
+	}
+
+	return compareInts(a.Build, b.Build)
+}
@@ -0,0 +1,157 @@
+package version
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchemeByName(t *testing.T) {
+	tt := []struct {
+		input   string
+		want    Scheme
+		wantErr bool
+	}{
+		{input: "", want: UList},
+		{input: "ulist", want: UList},
+		{input: "SemVer", want: SemVer},
+		{input: "calver", want: CalVer},
+		{input: "unknown", wantErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := SchemeByName(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("SchemeByName() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && got.Name() != tc.want.Name() {
+				t.Errorf("SchemeByName() = %s, want %s", got.Name(), tc.want.Name())
+			}
+		})
+	}
+}
+
+func TestSchemeParseAndFormat(t *testing.T) {
+	tt := []struct {
+		name    string
+		scheme  Scheme
+		input   string
+		want    Version
+		wantStr string
+		wantErr bool
+	}{
+		{
+			name:    "ulist",
+			scheme:  UList,
+			input:   "version: 2025.200.01+10",
+			want:    Version{Year: 2025, Major: 200, Minor: 1, Build: 10},
+			wantStr: "2025.200.01+10",
+		},
+		{
+			name:    "semver with build",
+			scheme:  SemVer,
+			input:   "version: 1.4.2+17",
+			want:    Version{Year: 1, Major: 4, Minor: 2, Build: 17},
+			wantStr: "1.4.2+17",
+		},
+		{
+			name:    "semver without build",
+			scheme:  SemVer,
+			input:   "10.0.3",
+			want:    Version{Year: 10, Major: 0, Minor: 3},
+			wantStr: "10.0.3",
+		},
+		{
+			name:    "semver invalid",
+			scheme:  SemVer,
+			input:   "version: 1.2",
+			wantErr: true,
+		},
+		{
+			name:    "calver",
+			scheme:  CalVer,
+			input:   "version: 25.04.2+31",
+			want:    Version{Year: 25, Major: 4, Minor: 2, Build: 31},
+			wantStr: "25.04.2+31",
+		},
+		{
+			name:    "calver invalid month",
+			scheme:  CalVer,
+			input:   "version: 25.13.0",
+			wantErr: true,
+		},
+		{
+			name:    "calver does not match ulist",
+			scheme:  CalVer,
+			input:   "version: 2025.200.01+10",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.scheme.Parse(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if !versionsEqual(*got, tc.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tc.want)
+			}
+			if got.String() != tc.wantStr {
+				t.Errorf("String() = %s, want %s", got.String(), tc.wantStr)
+			}
+		})
+	}
+}
+
+func TestSchemeComparable(t *testing.T) {
+	for _, scheme := range []Scheme{UList, SemVer, CalVer} {
+		v := Version{Year: 25, Major: 6, Minor: 1, Scheme: scheme}
+		if other := v; v != other {
+			t.Errorf("%s versions are not comparable", scheme.Name())
+		}
+	}
+}
+
+func TestSchemeBump(t *testing.T) {
+	defer func(restore func() time.Time) { now = restore }(now)
+	now = func() time.Time {
+		return time.Date(2025, time.June, 10, 0, 0, 0, 0, time.UTC)
+	}
+
+	tt := []struct {
+		name     string
+		scheme   Scheme
+		version  Version
+		bumpType BumpType
+		want     string
+		wantErr  bool
+	}{
+		{name: "semver major", scheme: SemVer, version: Version{Year: 1, Major: 4, Minor: 2, Build: 7}, bumpType: BumpTypeMajor, want: "2.0.0+1"},
+		{name: "semver minor", scheme: SemVer, version: Version{Year: 1, Major: 4, Minor: 2, Build: 7}, bumpType: BumpTypeMinor, want: "1.5.0+1"},
+		{name: "semver patch", scheme: SemVer, version: Version{Year: 1, Major: 4, Minor: 2, Build: 7}, bumpType: BumpTypePatch, want: "1.4.3+1"},
+		{name: "semver build", scheme: SemVer, version: Version{Year: 1, Major: 4, Minor: 2, Build: 7}, bumpType: BumpTypeBuild, want: "1.4.2+8"},
+		{name: "semver year unsupported", scheme: SemVer, version: Version{Year: 1}, bumpType: BumpTypeYear, wantErr: true},
+		{name: "ulist patch unsupported", scheme: UList, version: Version{Year: 2025}, bumpType: BumpTypePatch, wantErr: true},
+		{name: "calver new period", scheme: CalVer, version: Version{Year: 25, Major: 4, Minor: 2, Build: 7}, bumpType: BumpTypeMajor, want: "25.06.0+1"},
+		{name: "calver same period", scheme: CalVer, version: Version{Year: 25, Major: 6, Minor: 2, Build: 7}, bumpType: BumpTypeMajor, wantErr: true},
+		{name: "calver patch", scheme: CalVer, version: Version{Year: 25, Major: 4, Minor: 2, Build: 7}, bumpType: BumpTypePatch, want: "25.04.3+1"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v := tc.version
+			v.Scheme = tc.scheme
+			err := v.Bump(tc.bumpType)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Bump() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && v.String() != tc.want {
+				t.Errorf("Bump() = %s, want %s", v.String(), tc.want)
+			}
+		})
+	}
+}
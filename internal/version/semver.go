@@ -0,0 +1,107 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// SemVer is the semantic versioning scheme "major.minor.patch+build", e.g. "1.4.2+17".
+// The components are stored as Year (major), Major (minor) and Minor (patch),
+// the build number is optional.
+var SemVer Scheme = semVerScheme{}
+
+var semVerRegex = regexp.MustCompile(`\b(\d+)\.(\d+)\.(\d+)` + preReleasePattern + `(?:\+(\d+))?`)
+
+type semVerScheme struct{}
+
+func (semVerScheme) Name() string {
+	return "semver"
+}
+
+func (s semVerScheme) Parse(line string) (*Version, error) {
+	if len(line) == 0 {
+		return nil, errors.New("Version line string cannot be empty")
+	}
+	matches := semVerRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, fmt.Errorf("Version string doesn't match expected format \"version: 1.2.3+4\", got: %s", line)
+	}
+
+	numbers, err := atoiAll([]string{matches[1], matches[2], matches[3], matches[5]})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Version{
+		Year:       numbers[0],
+		Major:      numbers[1],
+		Minor:      numbers[2],
+		PreRelease: matches[4],
+		Build:      numbers[3],
+		Scheme:     s,
+	}, nil
+}
+
+func (semVerScheme) Format(v Version) string {
+	return withBuild(withPreRelease(fmt.Sprintf("%d.%d.%d", v.Year, v.Major, v.Minor), v.PreRelease), v.Build)
+}
+
+// Bump follows semantic versioning: major resets minor and patch, minor resets
+// patch. Any bump other than build resets the build number to 1.
+func (semVerScheme) Bump(v *Version, bumpType BumpType) error {
+	switch bumpType {
+	case BumpTypeBuild:
+		v.Build++
+		return nil
+
+	case BumpTypePatch:
+		v.Minor++
+
+	case BumpTypeMinor:
+		v.Major++
+		v.Minor = 0
+
+	case BumpTypeMajor:
+		v.Year++
+		v.Major = 0
+		v.Minor = 0
+
+	default:
+		return fmt.Errorf("bump type %s is not supported by the semver version scheme", bumpType)
+	}
+
+	v.Build = 1
+	return nil
+}
+
+func (semVerScheme) Compare(a, b Version) int {
+	return compareComponents(a, b)
+}
+
+// atoiAll converts the matched components, empty optional components are zero.
+func atoiAll(values []string) ([]int, error) {
+	numbers := make([]int, len(values))
+	for i, value := range values {
+		if len(value) == 0 {
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version component (%s): %q", value, err)
+		}
+		numbers[i] = n
+	}
+
+	return numbers, nil
+}
+
+// withBuild appends the build number when it is set.
+func withBuild(s string, build int) string {
+	if build == 0 {
+		return s
+	}
+	return fmt.Sprintf("%s+%d", s, build)
+}
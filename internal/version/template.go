@@ -0,0 +1,25 @@
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Placeholders supported by Expand.
+const (
+	PlaceholderVersion        = "{version}"
+	PlaceholderVersionNoBuild = "{version_no_build}"
+	PlaceholderBuild          = "{build}"
+	PlaceholderPreRelease     = "{pre_release}"
+)
+
+// Expand replaces the placeholders of the template with the version, e.g.
+// "QA-v{version}" -> "QA-v2025.200.01+04" and "{build}" -> "4".
+func (v Version) Expand(template string) string {
+	return strings.NewReplacer(
+		PlaceholderVersion, v.String(),
+		PlaceholderVersionNoBuild, v.StringNoBuild(),
+		PlaceholderBuild, strconv.Itoa(v.Build),
+		PlaceholderPreRelease, v.PreRelease,
+	).Replace(template)
+}
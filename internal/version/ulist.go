@@ -0,0 +1,108 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// UList is the calendar based uList scheme "YYYY.MMM.mm+bb", e.g. "2025.200.01+04"
+// or "2025.200.01-rc.2+04" for pre-releases.
+// Milestones are the hundreds of the major component.
+var UList Scheme = ulistScheme{}
+
+var ulistRegex = regexp.MustCompile(`(\d\d\d\d)\.(\d\d+)\.(\d+)` + preReleasePattern + `\+(\d+)`)
+
+type ulistScheme struct{}
+
+func (ulistScheme) Name() string {
+	return "ulist"
+}
+
+// Parse parses a version line like "version: 2020.100.03+04".
+// It expects the version to be in the format "year.major.minor+build" where all
+// components are integers. Returns an error if the format is invalid or any component
+// cannot be parsed as an integer.
+func (s ulistScheme) Parse(line string) (*Version, error) {
+	if len(line) == 0 {
+		return nil, errors.New("Version line string cannot be empty")
+	}
+	matches := ulistRegex.FindStringSubmatch(line)
+	if matches == nil || len(matches) != 6 {
+		return nil, fmt.Errorf("Version string doesn't match expected format \"version: 2020.100.01+01\", got: %s", line)
+	}
+
+	year, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid year version (%s): %q", matches[1], err)
+	}
+
+	major, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid major version (%s): %q", matches[2], err)
+	}
+
+	minor, err := strconv.Atoi(matches[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid minor version (%s): %q", matches[3], err)
+	}
+
+	build, err := strconv.Atoi(matches[5])
+	if err != nil {
+		return nil, fmt.Errorf("invalid build number (%s): %q", matches[5], err)
+	}
+
+	version := Version{
+		Year:       year,
+		Major:      major,
+		Minor:      minor,
+		PreRelease: matches[4],
+		Build:      build,
+		Scheme:     s,
+	}
+
+	return &version, nil
+}
+
+func (ulistScheme) Format(v Version) string {
+	version := withPreRelease(fmt.Sprintf("%04d.%03d.%02d", v.Year, v.Major, v.Minor), v.PreRelease)
+	return fmt.Sprintf("%s+%02d", version, v.Build)
+}
+
+// Bump supports bumping the build number, minor, major, milestone or year components.
+func (ulistScheme) Bump(v *Version, bumpType BumpType) error {
+	switch bumpType {
+	case BumpTypeBuild:
+		v.Build++
+
+	case BumpTypeMinor:
+		v.Build = 1
+		v.Minor++
+
+	case BumpTypeMajor:
+		v.Build = 1
+		v.Minor = 1
+		v.Major++
+
+	case BumpTypeMilestone:
+		v.Build = 1
+		v.Minor = 1
+		v.Major = v.Major - (v.Major % 100) + 100
+
+	case BumpTypeYear:
+		v.Build = 1
+		v.Minor = 1
+		v.Major = 100
+		v.Year++
+
+	default:
+		return fmt.Errorf("bump type %s is not supported by the ulist version scheme", bumpType)
+	}
+
+	return nil
+}
+
+func (ulistScheme) Compare(a, b Version) int {
+	return compareComponents(a, b)
+}
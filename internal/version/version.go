@@ -3,99 +3,74 @@ package version
 import (
 	"errors"
 	"fmt"
-	"regexp"
-	"strconv"
+	"strings"
 )
 
-// Version represents semantic versioning with build number
+// Version represents a release version made of three numeric components and a
+// build number. The field names follow the uList scheme (YYYY.MMM.mm+bb), other
+// schemes store their components positionally (see Scheme).
 type Version struct {
 	Year  int
 	Major int
 	Minor int
-	Build int
+	// PreRelease is the optional pre-release channel and number, e.g. "rc.2".
+	PreRelease string
+	Build      int
+	// Scheme used to format, bump and compare the version, nil means Default().
+	Scheme Scheme
+}
+
+func (v Version) scheme() Scheme {
+	if v.Scheme != nil {
+		return v.Scheme
+	}
+	return Default()
 }
 
 // String returns formatted version string like "2010.200.01+04"
 func (v Version) String() string {
-	return fmt.Sprintf("%04d.%03d.%02d+%02d", v.Year, v.Major, v.Minor, v.Build)
+	return v.scheme().Format(v)
 }
 
 // String returns formatted version string without the build part like "2010.200.01"
 func (v Version) StringNoBuild() string {
-	return fmt.Sprintf("%04d.%03d.%02d", v.Year, v.Major, v.Minor)
+	noBuild, _, _ := strings.Cut(v.String(), "+")
+	return noBuild
 }
 
-// Parse parses a version line like "version: 10.20.03+04" into a Version struct.
-// It expects the version to be in the format "major.minor.patch+build" where all
-// components are integers. Returns an error if the format is invalid or any component
-// cannot be parsed as an integer.
+// Parse parses a version line like "version: 2020.100.03+04" into a Version struct
+// using the Default() scheme. Returns an error if the format is invalid or any
+// component cannot be parsed as an integer.
 func Parse(line string) (*Version, error) {
-	if len(line) == 0 {
-		return nil, errors.New("Version line string cannot be empty")
-	}
-	re := regexp.MustCompile(`(\d\d\d\d)\.(\d\d+)\.(\d+)\+(\d+)`)
-	matches := re.FindStringSubmatch(line)
-	if matches == nil || len(matches) != 5 {
-		return nil, fmt.Errorf("Version string doesn't match expected format \"version: 2020.100.01+01\", got: %s", line)
-	}
-
-	major, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return nil, fmt.Errorf("invalid major version (%s): %q", matches[1], err)
-	}
-
-	minor, err := strconv.Atoi(matches[2])
-	if err != nil {
-		return nil, fmt.Errorf("invalid minor version (%s): %q", matches[2], err)
-	}
-
-	patch, err := strconv.Atoi(matches[3])
-	if err != nil {
-		return nil, fmt.Errorf("invalid patch version (%s): %q", matches[3], err)
-	}
-
-	build, err := strconv.Atoi(matches[4])
-	if err != nil {
-		return nil, fmt.Errorf("invalid build number (%s): %q", matches[4], err)
-	}
-
-	version := Version{
-		Year:  major,
-		Major: minor,
-		Minor: patch,
-		Build: build,
-	}
-
-	return &version, nil
+	return Default().Parse(line)
 }
 
-// Bump updates the provided Version struct based on the bump type specified
-// It supports bumping the build number, patch, minor, or major version components.
-func (v *Version) Bump(bumpType BumpType) {
+// Bump updates the provided Version struct based on the bump type specified.
+// Pre-release bumps are handled for every scheme: prerelease increments the
+// pre-release number and promote drops the pre-release. Bumping the version
+// numbers also drops the pre-release.
+// Returns an error if the version scheme doesn't support the bump type.
+func (v *Version) Bump(bumpType BumpType) error {
 	switch bumpType {
-	case BumpTypeBuild:
-		v.Build++
-
-	case BumpTypeMinor:
-		v.Build = 1
-		v.Minor++
+	case BumpTypePreRelease:
+		return v.bumpPreRelease()
 
-	case BumpTypeMajor:
-		v.Build = 1
-		v.Minor = 1
-		v.Major++
+	case BumpTypePromote:
+		if !v.IsPreRelease() {
+			return fmt.Errorf("version %s is not a pre-release and cannot be promoted", v)
+		}
+		v.PreRelease = ""
+		return nil
 
-	case BumpTypeMilestone:
-		v.Build = 1
-		v.Minor = 1
-		v.Major = v.Major - (v.Major % 100) + 100
+	case BumpTypeBuild:
+		return v.scheme().Bump(v, bumpType)
+	}
 
-	case BumpTypeYear:
-		v.Build = 1
-		v.Minor = 1
-		v.Major = 100
-		v.Year++
+	if err := v.scheme().Bump(v, bumpType); err != nil {
+		return err
 	}
+	v.PreRelease = ""
+	return nil
 }
 
 // FetchFromLines searches through the lines to find the version line.
@@ -103,8 +78,8 @@ func (v *Version) Bump(bumpType BumpType) {
 // Returns an error if the version line is not found or if parsing fails.
 func FetchFromLines(lines []string) (*Version, int, error) {
 	for i, line := range lines {
-		// the minimum string containing a version (0.0.0+00) has 8 characters
-		if len(line) < 8 {
+		// the minimum string containing a version (0.0.0) has 5 characters
+		if len(line) < 5 {
 			continue
 		}
 
@@ -135,6 +135,17 @@ func TestParse(t *testing.T) {
 				Build: 456,
 			},
 		},
+		{
+			name:  "valid pre-release",
+			input: "version: 2025.200.01-rc.2+05",
+			want: Version{
+				Year:       2025,
+				Major:      200,
+				Minor:      01,
+				PreRelease: "rc.2",
+				Build:      05,
+			},
+		},
 		{
 			name:  "valid semver with actual value",
 			input: "version: 2025.200.01+10",
@@ -232,5 +243,6 @@ func versionsEqual(a, b Version) bool {
 	return a.Year == b.Year &&
 		a.Major == b.Major &&
 		a.Minor == b.Minor &&
+		a.PreRelease == b.PreRelease &&
 		a.Build == b.Build
 }
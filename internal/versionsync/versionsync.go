@@ -0,0 +1,400 @@
+// Package versionsync propagates the pubspec.yaml version into native
+// project files (Gradle, Info.plist, xcconfig and generated Dart files)
+// configured as sync targets in .ult.yaml.
+package versionsync
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ulist.app/ult/internal/config"
+	"ulist.app/ult/internal/dryrun"
+	"ulist.app/ult/internal/logging"
+	"ulist.app/ult/internal/pubspec"
+	"ulist.app/ult/internal/version"
+)
+
+// ConfigKey is the .ult.yaml key holding the list of sync targets.
+const ConfigKey = "version-sync"
+
+// Kind is the syntax of a sync target file.
+type Kind string
+
+const (
+	KindGradle   Kind = "gradle"
+	KindPlist    Kind = "plist"
+	KindXCConfig Kind = "xcconfig"
+	KindDart     Kind = "dart"
+)
+
+const defaultDartTemplate = `// Code generated by ult. DO NOT EDIT.
+
+const appVersion = '{version}';
+const appVersionName = '{version_no_build}';
+const appBuildNumber = {build};
+`
+
+var (
+	logger = logging.New("versionsync")
+)
+
+// Target is a file that holds a copy of the version. Name and Number are
+// templates (see version.Expand) for the version name and the build number,
+// NameKey and NumberKey the keys they are assigned to. Dart targets are
+// generated entirely from Template.
+type Target struct {
+	Path      string `yaml:"path"`
+	Kind      Kind   `yaml:"kind"`
+	Name      string `yaml:"name"`
+	Number    string `yaml:"number"`
+	NameKey   string `yaml:"name-key"`
+	NumberKey string `yaml:"number-key"`
+	Template  string `yaml:"template"`
+}
+
+// Change is the new content of a file.
+type Change struct {
+	Path   string
+	Before []byte
+	After  []byte
+	exists bool
+	mode   os.FileMode
+}
+
+// Changed reports whether the file content differs from the new content.
+func (c Change) Changed() bool {
+	return !bytes.Equal(c.Before, c.After)
+}
+
+// Exists reports whether the file existed before the change.
+func (c Change) Exists() bool {
+	return c.exists
+}
+
+// LoadTargets reads the sync targets from the configuration and fills the
+// defaults of every target.
+func LoadTargets(cfg *config.Config) ([]Target, error) {
+	targets := []Target{}
+	if _, err := cfg.Decode(ConfigKey, &targets); err != nil {
+		return nil, err
+	}
+
+	for i := range targets {
+		if err := targets[i].setDefaults(); err != nil {
+			return nil, err
+		}
+	}
+	return targets, nil
+}
+
+func (t *Target) setDefaults() error {
+	if len(t.Path) == 0 {
+		return errors.New("version sync target without a path")
+	}
+
+	if len(t.Kind) == 0 {
+		t.Kind = kindFromPath(t.Path)
+	}
+	if len(t.Name) == 0 {
+		t.Name = version.PlaceholderVersionNoBuild
+	}
+	if len(t.Number) == 0 {
+		t.Number = version.PlaceholderBuild
+	}
+
+	switch t.Kind {
+	case KindGradle:
+		t.NameKey = withDefault(t.NameKey, "versionName")
+		t.NumberKey = withDefault(t.NumberKey, "versionCode")
+	case KindPlist:
+		t.NameKey = withDefault(t.NameKey, "CFBundleShortVersionString")
+		t.NumberKey = withDefault(t.NumberKey, "CFBundleVersion")
+	case KindXCConfig:
+		t.NameKey = withDefault(t.NameKey, "FLUTTER_BUILD_NAME")
+		t.NumberKey = withDefault(t.NumberKey, "FLUTTER_BUILD_NUMBER")
+	case KindDart:
+		t.Template = withDefault(t.Template, defaultDartTemplate)
+	default:
+		return fmt.Errorf("version sync target %s: unknown kind '%s' (valid kinds: gradle, plist, xcconfig, dart)", t.Path, t.Kind)
+	}
+	return nil
+}
+
+func kindFromPath(path string) Kind {
+	switch {
+	case strings.HasSuffix(path, ".gradle"), strings.HasSuffix(path, ".gradle.kts"):
+		return KindGradle
+	case strings.HasSuffix(path, ".plist"):
+		return KindPlist
+	case strings.HasSuffix(path, ".xcconfig"):
+		return KindXCConfig
+	case strings.HasSuffix(path, ".dart"):
+		return KindDart
+	}
+	return ""
+}
+
+// Reader returns the content of the file at path, ok is false when the file
+// does not exist.
+type Reader func(path string) (content []byte, ok bool, err error)
+
+// ReadLocal reads files from the working tree.
+func ReadLocal(path string) ([]byte, bool, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return content, true, nil
+}
+
+// Plan computes the new content of every target for the version without
+// writing anything. Returns an error if a target cannot be read or its
+// version keys are not found.
+func Plan(targets []Target, v version.Version) ([]Change, error) {
+	changes, err := PlanWith(ReadLocal, targets, v)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range changes {
+		if info, err := os.Stat(changes[i].Path); err == nil {
+			changes[i].mode = info.Mode()
+		}
+	}
+	return changes, nil
+}
+
+// PlanWith is Plan reading the current content of the targets with read,
+// e.g. from a remote repository.
+func PlanWith(read Reader, targets []Target, v version.Version) ([]Change, error) {
+	changes := make([]Change, 0, len(targets))
+	for _, target := range targets {
+		change, err := target.plan(read, v)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func (t Target) plan(read Reader, v version.Version) (Change, error) {
+	change := Change{Path: t.Path, mode: 0o644}
+
+	var err error
+	change.Before, change.exists, err = read(t.Path)
+	if err != nil {
+		return change, err
+	}
+	// generated files are created when missing
+	if !change.exists && t.Kind != KindDart {
+		return change, fmt.Errorf("reading %s: %w", t.Path, os.ErrNotExist)
+	}
+
+	name, number := v.Expand(t.Name), v.Expand(t.Number)
+
+	switch t.Kind {
+	case KindDart:
+		change.After = []byte(v.Expand(t.Template))
+		return change, nil
+
+	case KindGradle:
+		// Groovy (versionName "1.0") and Kotlin script (versionName = "1.0")
+		gradleValue := func(key string) string {
+			return `(?m)(^[ \t]*` + regexp.QuoteMeta(key) + `\b[ \t]*=?[ \t]*)(\S+)`
+		}
+		change.After, err = replaceAll(change.Before, t.Path, t.NameKey, gradleValue(t.NameKey), `"`+name+`"`)
+		if err == nil {
+			change.After, err = replaceAll(change.After, t.Path, t.NumberKey, gradleValue(t.NumberKey), number)
+		}
+
+	case KindPlist:
+		plistValue := func(key string) string {
+			return `(<key>` + regexp.QuoteMeta(key) + `</key>\s*<string>)([^<]*)`
+		}
+		change.After, err = replaceAll(change.Before, t.Path, t.NameKey, plistValue(t.NameKey), name)
+		if err == nil {
+			change.After, err = replaceAll(change.After, t.Path, t.NumberKey, plistValue(t.NumberKey), number)
+		}
+
+	case KindXCConfig:
+		xcconfigValue := func(key string) string {
+			return `(?m)(^[ \t]*` + regexp.QuoteMeta(key) + `[ \t]*=[ \t]*)([^\s/]*)`
+		}
+		change.After, err = replaceAll(change.Before, t.Path, t.NameKey, xcconfigValue(t.NameKey), name)
+		if err == nil {
+			change.After, err = replaceAll(change.After, t.Path, t.NumberKey, xcconfigValue(t.NumberKey), number)
+		}
+	}
+
+	return change, err
+}
+
+// replaceAll replaces the second group of every match of the pattern with
+// the value. Returns an error if the key is not found.
+func replaceAll(content []byte, path, key, pattern, value string) ([]byte, error) {
+	regex := regexp.MustCompile(pattern)
+	if !regex.Match(content) {
+		return nil, fmt.Errorf("%s: version key '%s' not found", path, key)
+	}
+
+	return regex.ReplaceAllFunc(content, func(match []byte) []byte {
+		groups := regex.FindSubmatch(match)
+		return append(append([]byte{}, groups[1]...), value...)
+	}), nil
+}
+
+// Apply writes every changed file. The files are first written next to the
+// originals and then renamed over them, if any step fails the files already
+// replaced are restored so either all files are updated or none.
+func Apply(changes []Change) error {
+	pending := []Change{}
+	for _, change := range changes {
+		if change.Changed() {
+			pending = append(pending, change)
+		}
+	}
+
+	if dryrun.Enabled() {
+		for _, change := range pending {
+			if !change.exists {
+				dryrun.Printf("create %s (%d bytes)", change.Path, len(change.After))
+				continue
+			}
+			for _, line := range changedLines(change) {
+				dryrun.Printf("write %s %s", change.Path, line)
+			}
+		}
+		return nil
+	}
+
+	temps := make([]string, 0, len(pending))
+	defer func() {
+		for _, temp := range temps {
+			os.Remove(temp)
+		}
+	}()
+
+	for _, change := range pending {
+		temp, err := writeTemp(change)
+		if err != nil {
+			return err
+		}
+		temps = append(temps, temp)
+	}
+
+	for i, change := range pending {
+		if err := os.Rename(temps[i], change.Path); err != nil {
+			rollback(pending[:i])
+			return fmt.Errorf("replacing %s: %w", change.Path, err)
+		}
+		logger.Info("updated version", "path", change.Path)
+	}
+
+	return nil
+}
+
+func writeTemp(change Change) (string, error) {
+	dir := filepath.Dir(change.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("creating directory %s: %w", dir, err)
+	}
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(change.Path)+".*")
+	if err != nil {
+		return "", fmt.Errorf("creating temporary file for %s: %w", change.Path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(change.After); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("writing %s: %w", f.Name(), err)
+	}
+	if err := f.Chmod(change.mode); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("setting permissions of %s: %w", f.Name(), err)
+	}
+	return f.Name(), nil
+}
+
+func rollback(applied []Change) {
+	for _, change := range applied {
+		var err error
+		if !change.exists {
+			err = os.Remove(change.Path)
+		} else {
+			err = os.WriteFile(change.Path, change.Before, change.mode)
+		}
+		if err != nil {
+			logger.Error("failed to restore file", "path", change.Path, "error", err)
+		}
+	}
+}
+
+// Update writes the version into the pubspec and every target, either all
+// files are updated or none.
+func Update(file *pubspec.File, targets []Target, v version.Version) error {
+	file.SetVersion(v)
+	if len(targets) == 0 {
+		return file.Write()
+	}
+
+	changes, err := Plan(targets, v)
+	if err != nil {
+		return err
+	}
+
+	if dryrun.Enabled() {
+		if err := file.Write(); err != nil {
+			return err
+		}
+		return Apply(changes)
+	}
+
+	pubspecChange, err := newChange(file.Path, file.Bytes())
+	if err != nil {
+		return err
+	}
+	return Apply(append([]Change{pubspecChange}, changes...))
+}
+
+func newChange(path string, after []byte) (Change, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Change{}, fmt.Errorf("getting file info: %w", err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		return Change{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return Change{Path: path, Before: before, After: after, exists: true, mode: info.Mode()}, nil
+}
+
+// changedLines returns the new lines that differ from the old content.
+func changedLines(change Change) []string {
+	before := strings.Split(string(change.Before), "\n")
+	after := strings.Split(string(change.After), "\n")
+
+	lines := []string{}
+	for i, line := range after {
+		if i >= len(before) || before[i] != line {
+			lines = append(lines, fmt.Sprintf("line %d: %s", i+1, strings.TrimSpace(line)))
+		}
+	}
+	return lines
+}
+
+func withDefault(value, fallback string) string {
+	if len(value) == 0 {
+		return fallback
+	}
+	return value
+}
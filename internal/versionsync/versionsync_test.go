@@ -0,0 +1,174 @@
+package versionsync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ulist.app/ult/internal/version"
+)
+
+func TestPlan(t *testing.T) {
+	v := version.Version{Year: 2025, Major: 200, Minor: 2, PreRelease: "rc.1", Build: 7}
+
+	tt := []struct {
+		name    string
+		file    string
+		target  Target
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "gradle",
+			file: "build.gradle",
+			content: "defaultConfig {\n" +
+				"    versionCode flutterVersionCode.toInteger()\n" +
+				"    versionName flutterVersionName\n" +
+				"    versionNameSuffix \"-dev\"\n" +
+				"}\n",
+			want: "defaultConfig {\n" +
+				"    versionCode 7\n" +
+				"    versionName \"2025.200.02-rc.1\"\n" +
+				"    versionNameSuffix \"-dev\"\n" +
+				"}\n",
+		},
+		{
+			name: "gradle kts with template",
+			file: "build.gradle.kts",
+			target: Target{
+				Name: "{version}",
+			},
+			content: "defaultConfig {\n" +
+				"    versionCode = flutter.versionCode\n" +
+				"    versionName = flutter.versionName\n" +
+				"}\n",
+			want: "defaultConfig {\n" +
+				"    versionCode = 7\n" +
+				"    versionName = \"2025.200.02-rc.1+07\"\n" +
+				"}\n",
+		},
+		{
+			name: "plist",
+			file: "Info.plist",
+			content: "<dict>\n" +
+				"\t<key>CFBundleShortVersionString</key>\n" +
+				"\t<string>$(FLUTTER_BUILD_NAME)</string>\n" +
+				"\t<key>CFBundleVersion</key>\n" +
+				"\t<string>1</string>\n" +
+				"</dict>\n",
+			want: "<dict>\n" +
+				"\t<key>CFBundleShortVersionString</key>\n" +
+				"\t<string>2025.200.02-rc.1</string>\n" +
+				"\t<key>CFBundleVersion</key>\n" +
+				"\t<string>7</string>\n" +
+				"</dict>\n",
+		},
+		{
+			name: "xcconfig with custom keys",
+			file: "ShareExtension.xcconfig",
+			target: Target{
+				NameKey:   "MARKETING_VERSION",
+				NumberKey: "CURRENT_PROJECT_VERSION",
+			},
+			content: "#include \"Generated.xcconfig\"\n" +
+				"MARKETING_VERSION = 1.0.0 // set by ult\n" +
+				"CURRENT_PROJECT_VERSION=1\n",
+			want: "#include \"Generated.xcconfig\"\n" +
+				"MARKETING_VERSION = 2025.200.02-rc.1 // set by ult\n" +
+				"CURRENT_PROJECT_VERSION=7\n",
+		},
+		{
+			name:   "dart",
+			file:   "version.g.dart",
+			target: Target{Template: "const version = '{version}';\n"},
+			want:   "const version = '2025.200.02-rc.1+07';\n",
+		},
+		{
+			name:    "missing key",
+			file:    "Info.plist",
+			content: "<dict>\n</dict>\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			if len(tc.content) > 0 {
+				if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			target := tc.target
+			target.Path = path
+			if err := target.setDefaults(); err != nil {
+				t.Fatalf("setDefaults() error = %v", err)
+			}
+
+			changes, err := Plan([]Target{target}, v)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Plan() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+
+			if got := string(changes[0].After); got != tc.want {
+				t.Errorf("Plan() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := t.TempDir()
+	plist := filepath.Join(dir, "Info.plist")
+	if err := os.WriteFile(plist, []byte("<key>CFBundleVersion</key><string>1</string><key>CFBundleShortVersionString</key><string>1.0</string>"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	targets := []Target{{Path: plist}, {Path: filepath.Join(dir, "lib", "version.g.dart")}}
+	for i := range targets {
+		if err := targets[i].setDefaults(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v := version.Version{Year: 2025, Major: 200, Minor: 2, Build: 7}
+	changes, err := Plan(targets, v)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if err := Apply(changes); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	info, err := os.Stat(plist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// applying the same version again finds no drift
+	changes, err = Plan(targets, v)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	for _, change := range changes {
+		if change.Changed() {
+			t.Errorf("%s changed after being synced", change.Path)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected only Info.plist and lib in %s, got %d entries", dir, len(entries))
+	}
+}
@@ -3,16 +3,19 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"io"
 	"os"
 
 	"github.com/urfave/cli/v3"
 	backend_command "ulist.app/ult/commands/backend"
 	commit_command "ulist.app/ult/commands/commit"
+	config_command "ulist.app/ult/commands/config"
 	release_command "ulist.app/ult/commands/release"
 	secrets_command "ulist.app/ult/commands/secrets"
 	tag_command "ulist.app/ult/commands/tag"
+	version_command "ulist.app/ult/commands/version"
 	"ulist.app/ult/internal/core"
+	"ulist.app/ult/internal/output"
 )
 
 var (
@@ -22,13 +25,25 @@ var (
 	commit string = "none"
 )
 
+type versionResult struct {
+	Version string `json:"version" yaml:"version"`
+	Commit  string `json:"commit" yaml:"commit"`
+}
+
 func main() {
 	versionCmd := cli.Command{
 		Name:  "version",
-		Usage: "show version information for ult",
+		Usage: "show version information for ult, or compare and sort versions of the project",
+		Commands: []*cli.Command{
+			&version_command.CompareCmd,
+			&version_command.SortCmd,
+		},
 		Action: func(ctx context.Context, c *cli.Command) error {
-			fmt.Println(version, commit)
-			return nil
+			result := versionResult{Version: version, Commit: commit}
+			return output.Print(result, func(w io.Writer) error {
+				_, err := fmt.Fprintln(w, result.Version, result.Commit)
+				return err
+			})
 		},
 	}
 
@@ -37,6 +52,7 @@ func main() {
 		&secrets_command.Cmd,
 		&commit_command.Cmd,
 		&tag_command.Cmd,
+		&config_command.Cmd,
 		&versionCmd,
 	}
 
@@ -51,11 +67,53 @@ func main() {
 			"The official CLI for managing uList application versions and deployments.\n" +
 			"Automates version bumping, release tagging, and deployment workflows.",
 		Commands: commands,
+		Before:   core.Setup,
 		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  core.ConfigFlag,
+				Usage: "path to the project configuration file (defaults to the nearest .ult.yaml)",
+			},
 			&cli.BoolFlag{
 				Name:    core.VerboseFlag,
 				Aliases: []string{"v"},
-				Usage:   "show logging messages",
+				Usage:   "show debug logging messages",
+			},
+			&cli.BoolFlag{
+				Name:    core.QuietFlag,
+				Aliases: []string{"q"},
+				Usage:   "only show error logging messages",
+			},
+			&cli.StringFlag{
+				Name:  core.LogFormatFlag,
+				Usage: "format of the logging messages written to stderr: text, json or logfmt",
+				Value: "text",
+			},
+			&cli.StringFlag{
+				Name:    core.OutputFlag,
+				Aliases: []string{"o"},
+				Usage:   "format of the command results: table, json or yaml",
+				Value:   "table",
+			},
+			&cli.StringFlag{
+				Name:  core.VersionSchemeFlag,
+				Usage: "version scheme of the project: ulist (YYYY.MMM.mm+bb), semver (1.2.3+4) or calver (YY.MM.patch+build)",
+				Value: "ulist",
+			},
+			&cli.BoolFlag{
+				Name:  core.DryRunFlag,
+				Usage: "print what would be written, pushed, uploaded or inserted without doing it",
+			},
+			&cli.StringFlag{
+				Name:  core.BackendFlag,
+				Usage: "how commands access the repository: git (the local clone) or gitlab (the GitLab API, requires --token and --project-id); defaults to the mode of each command",
+			},
+			&cli.StringFlag{
+				Name:  core.SignFlag,
+				Usage: "sign the commits and tags created with git: gpg or ssh",
+			},
+			&cli.StringFlag{
+				Name:  core.SigningKeyFlag,
+				Usage: "key id (gpg) or public key file (ssh) to sign with, defaults to user.signingkey of the git configuration",
 			},
 			&cli.StringFlag{
 				Name:  core.TokenFlag,
@@ -66,10 +124,19 @@ func main() {
 				Aliases: []string{"id"},
 				Usage:   "The ID or URL-encoded path of the project",
 			},
+			&cli.StringFlag{
+				Name:  core.GitlabURLFlag,
+				Usage: "base URL of the GitLab instance, for self-managed installations (defaults to https://gitlab.com)",
+			},
+			&cli.StringFlag{
+				Name:  core.CAFileFlag,
+				Usage: "path to a PEM encoded CA bundle trusted in addition to the system certificates",
+			},
 		},
 	}
 
 	if err := cmd.Run(context.Background(), os.Args); err != nil {
-		log.Fatal(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }